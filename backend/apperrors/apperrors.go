@@ -0,0 +1,151 @@
+// Package apperrors define los errores de aplicación que la API devuelve al
+// cliente: un código estable legible por máquinas, el status HTTP y un
+// mensaje para mostrar. Cualquier otro error se considera interno y nunca
+// se expone.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+
+	"proyecto-gym-backend/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Error struct {
+	Codigo   string
+	Status   int
+	Mensaje  string
+	Campos   []CampoError
+	Detalles map[string]interface{}
+	Causa    error
+}
+
+// CampoError describe un problema de validación en un campo del cuerpo.
+type CampoError struct {
+	Campo   string `json:"campo"`
+	Regla   string `json:"regla"`
+	Mensaje string `json:"mensaje"`
+
+	// Origen del mensaje, para poder traducirlo a otro idioma
+	validacion validator.FieldError
+	clave      string
+}
+
+func (e *Error) Error() string {
+	if e.Causa != nil {
+		return e.Codigo + ": " + e.Causa.Error()
+	}
+	return e.Codigo + ": " + e.Mensaje
+}
+
+func (e *Error) Unwrap() error {
+	return e.Causa
+}
+
+// Is compara por código, de modo que una copia con detalles sigue siendo
+// igual al error de catálogo del que se derivó.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Codigo == e.Codigo
+}
+
+// ConDetalle devuelve una copia del error con un dato adicional para el cliente.
+func (e *Error) ConDetalle(clave string, valor interface{}) *Error {
+	copia := *e
+	copia.Detalles = make(map[string]interface{}, len(e.Detalles)+1)
+	for k, v := range e.Detalles {
+		copia.Detalles[k] = v
+	}
+	copia.Detalles[clave] = valor
+	return &copia
+}
+
+// ConCausa devuelve una copia del error que conserva el error original para los logs.
+func (e *Error) ConCausa(causa error) *Error {
+	copia := *e
+	copia.Causa = causa
+	return &copia
+}
+
+func New(status int, codigo, mensaje string) *Error {
+	return &Error{Codigo: codigo, Status: status, Mensaje: mensaje}
+}
+
+func Invalido(codigo, mensaje string) *Error {
+	return New(http.StatusBadRequest, codigo, mensaje)
+}
+
+func NoAutorizado(codigo, mensaje string) *Error {
+	return New(http.StatusUnauthorized, codigo, mensaje)
+}
+
+func Prohibido(codigo, mensaje string) *Error {
+	return New(http.StatusForbidden, codigo, mensaje)
+}
+
+func NoEncontrado(codigo, mensaje string) *Error {
+	return New(http.StatusNotFound, codigo, mensaje)
+}
+
+func Conflicto(codigo, mensaje string) *Error {
+	return New(http.StatusConflict, codigo, mensaje)
+}
+
+// Errores comunes a toda la API
+var (
+	ErrDatosInvalidos        = Invalido("DATOS_INVALIDOS", "Los datos enviados no son válidos")
+	ErrIDInvalido            = Invalido("ID_INVALIDO", "ID inválido")
+	ErrTokenRequerido        = NoAutorizado("TOKEN_REQUERIDO", "Token de autorización requerido")
+	ErrTokenInvalido         = NoAutorizado("TOKEN_INVALIDO", "Token inválido")
+	ErrPermisosInsuficientes = Prohibido("PERMISOS_INSUFICIENTES", "Permisos insuficientes")
+	ErrRutaNoEncontrada      = NoEncontrado("RUTA_NO_ENCONTRADA", "Ruta no encontrada")
+	ErrInterno               = New(http.StatusInternalServerError, "ERROR_INTERNO", "Error interno del servidor")
+)
+
+// Localizar devuelve una copia del error con el mensaje y los errores por
+// campo en el idioma indicado. Los detalles se usan como parámetros del mensaje.
+func (e *Error) Localizar(idioma string) *Error {
+	copia := *e
+	if i18n.Existe(e.Codigo) {
+		copia.Mensaje = i18n.T(idioma, e.Codigo, e.Detalles)
+	}
+
+	if e.Campos != nil {
+		copia.Campos = LocalizarCampos(e.Campos, idioma)
+	}
+	return &copia
+}
+
+// LocalizarCampos devuelve una copia de los errores por campo con los
+// mensajes en el idioma indicado.
+func LocalizarCampos(campos []CampoError, idioma string) []CampoError {
+	localizados := make([]CampoError, len(campos))
+	for i, campo := range campos {
+		switch {
+		case campo.validacion != nil:
+			campo.Mensaje = campo.validacion.Translate(i18n.Traductor(idioma))
+		case campo.clave != "":
+			campo.Mensaje = i18n.T(idioma, campo.clave, map[string]interface{}{"campo": campo.Campo})
+		}
+		localizados[i] = campo
+	}
+	return localizados
+}
+
+// Desde convierte cualquier error en un error de aplicación. Los errores que
+// no son de aplicación se reportan como ErrInterno conservando la causa.
+func Desde(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if campos, ok := camposInvalidos(err); ok {
+		e := *ErrDatosInvalidos
+		e.Campos = campos
+		e.Causa = err
+		return &e
+	}
+	return ErrInterno.ConCausa(err)
+}
@@ -0,0 +1,97 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"proyecto-gym-backend/i18n"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Reportar los campos con el nombre que usa el JSON y no el del struct
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			nombre := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if nombre == "-" {
+				return ""
+			}
+			if nombre == "" {
+				return f.Name
+			}
+			return nombre
+		})
+	}
+}
+
+// camposInvalidos traduce los errores de binding (validator y JSON mal
+// formado) a errores por campo.
+func camposInvalidos(err error) ([]CampoError, bool) {
+	var validacion validator.ValidationErrors
+	if errors.As(err, &validacion) {
+		campos := make([]CampoError, 0, len(validacion))
+		for _, fe := range validacion {
+			campos = append(campos, CampoError{
+				Campo:      fe.Field(),
+				Regla:      fe.Tag(),
+				Mensaje:    fe.Translate(i18n.Traductor(i18n.IdiomaPorDefecto)),
+				validacion: fe,
+			})
+		}
+		return campos, true
+	}
+
+	var tipo *json.UnmarshalTypeError
+	if errors.As(err, &tipo) {
+		clave := ClaveTipo(tipo.Type)
+		return []CampoError{{
+			Campo:   tipo.Field,
+			Regla:   "tipo",
+			Mensaje: i18n.T(i18n.IdiomaPorDefecto, clave, map[string]interface{}{"campo": tipo.Field}),
+			clave:   clave,
+		}}, true
+	}
+
+	var sintaxis *json.SyntaxError
+	if errors.As(err, &sintaxis) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return []CampoError{}, true
+	}
+
+	return nil, false
+}
+
+// NuevoCampoError arma un error de campo cuyo mensaje es el texto del
+// catálogo para la clave indicada. Se usa en validaciones que no pasan por
+// el validator, como las filas de un archivo importado.
+func NuevoCampoError(campo, regla, clave string) CampoError {
+	return CampoError{
+		Campo:   campo,
+		Regla:   regla,
+		Mensaje: i18n.T(i18n.IdiomaPorDefecto, clave, map[string]interface{}{"campo": campo}),
+		clave:   clave,
+	}
+}
+
+// ClaveTipo devuelve la clave del catálogo que describe el tipo esperado de un campo.
+func ClaveTipo(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "TIPO_TEXTO"
+	case reflect.Bool:
+		return "TIPO_BOOLEANO"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "TIPO_ENTERO"
+	case reflect.Float32, reflect.Float64:
+		return "TIPO_NUMERO"
+	case reflect.Slice, reflect.Array:
+		return "TIPO_LISTA"
+	default:
+		return "TIPO_OBJETO"
+	}
+}
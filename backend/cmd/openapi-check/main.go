@@ -0,0 +1,84 @@
+// openapi-check compara las rutas registradas en Gin con las documentadas en
+// docs/openapi.json y termina con error si alguna falta de un lado o del otro.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"proyecto-gym-backend/docs"
+	"proyecto-gym-backend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	prefijoAlias = "/api/"
+	prefijoV1    = "/api/v1/"
+)
+
+var parametroGin = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+func main() {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(docs.OpenAPI, &spec); err != nil {
+		fmt.Fprintln(os.Stderr, "openapi.json inválido:", err)
+		os.Exit(1)
+	}
+
+	documentadas := map[string]bool{}
+	for path, operaciones := range spec.Paths {
+		for metodo := range operaciones {
+			if metodo == "parameters" {
+				continue
+			}
+			documentadas[strings.ToUpper(metodo)+" "+path] = true
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	routes.Registrar(r)
+
+	registradas := map[string]bool{}
+	for _, ruta := range r.Routes() {
+		path := parametroGin.ReplaceAllString(ruta.Path, "{$1}")
+		registradas[ruta.Method+" "+path] = true
+	}
+
+	// El alias /api sólo se documenta a través de /api/v1
+	for ruta := range registradas {
+		metodo, path, _ := strings.Cut(ruta, " ")
+		if resto, ok := strings.CutPrefix(path, prefijoAlias); ok && !strings.HasPrefix(path, prefijoV1) &&
+			registradas[metodo+" "+prefijoV1+resto] {
+			delete(registradas, ruta)
+		}
+	}
+
+	var errores []string
+	for ruta := range registradas {
+		if !documentadas[ruta] {
+			errores = append(errores, "sin documentar: "+ruta)
+		}
+	}
+	for ruta := range documentadas {
+		if !registradas[ruta] {
+			errores = append(errores, "documentada pero no registrada: "+ruta)
+		}
+	}
+
+	if len(errores) > 0 {
+		sort.Strings(errores)
+		for _, e := range errores {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("openapi.json cubre las %d rutas registradas\n", len(registradas))
+}
@@ -0,0 +1,527 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Perfiles de ejecución
+const (
+	PerfilDev  = "dev"
+	PerfilTest = "test"
+	PerfilProd = "prod"
+)
+
+const jwtSecretoDesarrollo = "proyecto_gym_secreto_jwt_2024"
+
+// Config reúne toda la configuración del backend. Se carga una sola vez al
+// iniciar, en este orden de prioridad: valores por defecto del perfil,
+// archivo JSON, variables de entorno y flags de línea de comandos.
+type Config struct {
+	Perfil         string               `json:"perfil" env:"APP_PERFIL"`
+	Servidor       ServidorConfig       `json:"servidor"`
+	DB             DBConfig             `json:"db"`
+	JWT            JWTConfig            `json:"jwt"`
+	Log            LogConfig            `json:"log"`
+	Pagos          PagosConfig          `json:"pagos"`
+	Facturacion    FacturacionConfig    `json:"facturacion"`
+	Cancelacion    CancelacionConfig    `json:"cancelacion"`
+	Notificaciones NotificacionesConfig `json:"notificaciones"`
+	Recordatorios  RecordatoriosConfig  `json:"recordatorios"`
+	API            APIConfig            `json:"api"`
+	Calendario     CalendarioConfig     `json:"calendario"`
+	Disponibilidad DisponibilidadConfig `json:"disponibilidad"`
+	Idempotencia   IdempotenciaConfig   `json:"idempotencia"`
+	Limites        LimitesConfig        `json:"limites"`
+	Cuentas        CuentasConfig        `json:"cuentas"`
+}
+
+type ServidorConfig struct {
+	Puerto                    string   `json:"puerto" env:"PORT"`
+	CORSOrigenes              []string `json:"cors_origenes" env:"CORS_ORIGENES"`
+	ReadHeaderTimeoutSegundos int      `json:"read_header_timeout_segundos" env:"SERVER_READ_HEADER_TIMEOUT_SEGUNDOS"`
+	ReadTimeoutSegundos       int      `json:"read_timeout_segundos" env:"SERVER_READ_TIMEOUT_SEGUNDOS"`
+	WriteTimeoutSegundos      int      `json:"write_timeout_segundos" env:"SERVER_WRITE_TIMEOUT_SEGUNDOS"`
+	IdleTimeoutSegundos       int      `json:"idle_timeout_segundos" env:"SERVER_IDLE_TIMEOUT_SEGUNDOS"`
+	ShutdownTimeoutSegundos   int      `json:"shutdown_timeout_segundos" env:"SERVER_SHUTDOWN_TIMEOUT_SEGUNDOS"`
+	ProxiesConfiables         []string `json:"proxies_confiables" env:"TRUSTED_PROXIES"` // de quién se acepta X-Forwarded-For
+}
+
+type DBConfig struct {
+	Host                   string `json:"host" env:"DB_HOST"`
+	Puerto                 string `json:"puerto" env:"DB_PORT"`
+	Usuario                string `json:"usuario" env:"DB_USER"`
+	Password               string `json:"password" env:"DB_PASSWORD"`
+	Nombre                 string `json:"nombre" env:"DB_NAME"`
+	MaxOpenConns           int    `json:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns           int    `json:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetimeMinutos int    `json:"conn_max_lifetime_minutos" env:"DB_CONN_MAX_LIFETIME_MINUTOS"`
+}
+
+type JWTConfig struct {
+	Secreto         string `json:"secreto" env:"JWT_SECRET"`
+	ExpiracionHoras int    `json:"expiracion_horas" env:"JWT_EXPIRACION_HORAS"`
+}
+
+type LogConfig struct {
+	Nivel   string `json:"nivel" env:"LOG_LEVEL"`
+	Formato string `json:"formato" env:"LOG_FORMAT"`
+}
+
+type PagosConfig struct {
+	Proveedor      string `json:"proveedor" env:"PAGOS_PROVEEDOR"` // vacío deshabilita los pagos en línea
+	WebhookSecreto string `json:"webhook_secreto" env:"PAGOS_WEBHOOK_SECRET"`
+	PrecioClase    int    `json:"precio_clase" env:"PAGOS_PRECIO_CLASE"`
+}
+
+type FacturacionConfig struct {
+	RazonSocial string `json:"razon_social" env:"FACTURACION_RAZON_SOCIAL"`
+}
+
+type CancelacionConfig struct {
+	HorasLimite    int    `json:"horas_limite" env:"CANCELACION_HORAS_LIMITE"`
+	Modo           string `json:"modo" env:"CANCELACION_MODO"`
+	MaxFaltas      int    `json:"max_faltas" env:"PENALIZACION_MAX_FALTAS"`
+	VentanaDias    int    `json:"ventana_dias" env:"PENALIZACION_VENTANA_DIAS"`
+	SuspensionDias int    `json:"suspension_dias" env:"PENALIZACION_SUSPENSION_DIAS"`
+}
+
+type NotificacionesConfig struct {
+	Sender            string     `json:"sender" env:"NOTIFICACIONES_SENDER"`
+	Directorio        string     `json:"directorio" env:"NOTIFICACIONES_DIR"`
+	IntervaloSegundos int        `json:"intervalo_segundos" env:"NOTIFICACIONES_INTERVALO_SEGUNDOS"`
+	Lote              int        `json:"lote" env:"NOTIFICACIONES_LOTE"`
+	MaxIntentos       int        `json:"max_intentos" env:"NOTIFICACIONES_MAX_INTENTOS"`
+	SMTP              SMTPConfig `json:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host            string `json:"host" env:"SMTP_HOST"`
+	Puerto          string `json:"puerto" env:"SMTP_PORT"`
+	Usuario         string `json:"usuario" env:"SMTP_USUARIO"`
+	Password        string `json:"password" env:"SMTP_PASSWORD"`
+	Remitente       string `json:"remitente" env:"SMTP_REMITENTE"`
+	TimeoutSegundos int    `json:"timeout_segundos" env:"SMTP_TIMEOUT_SEGUNDOS"` // conexión, TLS y envío
+}
+
+type RecordatoriosConfig struct {
+	AnticipacionMinutos int `json:"anticipacion_minutos" env:"RECORDATORIOS_ANTICIPACION_MINUTOS"`
+	IntervaloSegundos   int `json:"intervalo_segundos" env:"RECORDATORIOS_INTERVALO_SEGUNDOS"`
+}
+
+// APIConfig controla el aviso de deprecación del alias /api. Las fechas van
+// en formato AAAA-MM-DD; vacías, el alias responde sin aviso.
+type APIConfig struct {
+	AliasDeprecacion string `json:"alias_deprecacion" env:"API_ALIAS_DEPRECACION"`
+	AliasSunset      string `json:"alias_sunset" env:"API_ALIAS_SUNSET"`
+}
+
+// CalendarioConfig define la zona horaria de las clases en los feeds iCal y
+// la URL pública con que se arman los enlaces de suscripción. Sin URL se usa
+// el host de la petición.
+type CalendarioConfig struct {
+	ZonaHoraria string `json:"zona_horaria" env:"CALENDARIO_ZONA_HORARIA"`
+	URLBase     string `json:"url_base" env:"CALENDARIO_URL_BASE"`
+}
+
+// DisponibilidadConfig define cómo se reparten los cambios de cupo entre
+// los clientes conectados. El broker "memoria" sirve para una sola instancia.
+type DisponibilidadConfig struct {
+	Broker            string `json:"broker" env:"DISPONIBILIDAD_BROKER"`
+	HeartbeatSegundos int    `json:"heartbeat_segundos" env:"DISPONIBILIDAD_HEARTBEAT_SEGUNDOS"`
+}
+
+// IdempotenciaConfig define cuánto se guardan las respuestas de los POST
+// enviados con Idempotency-Key.
+type IdempotenciaConfig struct {
+	TTLHoras int `json:"ttl_horas" env:"IDEMPOTENCIA_TTL_HORAS"`
+}
+
+// LimitesConfig define las cubetas de fichas que limitan los intentos de
+// login y los pedidos de recuperación de contraseña y de verificación de
+// email (capacidad y fichas que se recuperan por minuto) y el bloqueo progresivo de cuentas: a partir de
+// BloqueoIntentos fallos seguidos la cuenta se bloquea BloqueoMinutos, y
+// cada fallo posterior duplica el tiempo hasta BloqueoMaxMinutos.
+type LimitesConfig struct {
+	Almacen               string `json:"almacen" env:"LIMITES_ALMACEN"`
+	LoginIPCapacidad      int    `json:"login_ip_capacidad" env:"LIMITE_LOGIN_IP_CAPACIDAD"`
+	LoginIPPorMinuto      int    `json:"login_ip_por_minuto" env:"LIMITE_LOGIN_IP_POR_MINUTO"`
+	LoginCuentaCapacidad  int    `json:"login_cuenta_capacidad" env:"LIMITE_LOGIN_CUENTA_CAPACIDAD"`
+	LoginCuentaPorMinuto  int    `json:"login_cuenta_por_minuto" env:"LIMITE_LOGIN_CUENTA_POR_MINUTO"`
+	RecuperacionCapacidad int    `json:"recuperacion_capacidad" env:"LIMITE_RECUPERACION_CAPACIDAD"`
+	RecuperacionPorMinuto int    `json:"recuperacion_por_minuto" env:"LIMITE_RECUPERACION_POR_MINUTO"`
+	VerificacionCapacidad int    `json:"verificacion_capacidad" env:"LIMITE_VERIFICACION_CAPACIDAD"`
+	VerificacionPorMinuto int    `json:"verificacion_por_minuto" env:"LIMITE_VERIFICACION_POR_MINUTO"`
+	BloqueoIntentos       int    `json:"bloqueo_intentos" env:"BLOQUEO_INTENTOS"`
+	BloqueoMinutos        int    `json:"bloqueo_minutos" env:"BLOQUEO_MINUTOS"`
+	BloqueoMaxMinutos     int    `json:"bloqueo_max_minutos" env:"BLOQUEO_MAX_MINUTOS"`
+}
+
+// CuentasConfig define los enlaces que se envían por correo para gestionar
+// la cuenta: apuntan a páginas del frontend, que llaman luego a la API.
+type CuentasConfig struct {
+	URLFrontend         string `json:"url_frontend" env:"FRONTEND_URL"`
+	RecuperacionMinutos int    `json:"recuperacion_minutos" env:"RECUPERACION_EXPIRACION_MINUTOS"`
+	VerificacionHoras   int    `json:"verificacion_horas" env:"VERIFICACION_EXPIRACION_HORAS"`
+}
+
+// FormatoFecha es el formato de las fechas en la configuración.
+const FormatoFecha = "2006-01-02"
+
+// App es la configuración cargada al iniciar el proceso.
+var App = Defaults(PerfilDev)
+
+// Defaults devuelve la configuración por defecto de un perfil.
+func Defaults(perfil string) *Config {
+	c := &Config{
+		Perfil: perfil,
+		Servidor: ServidorConfig{
+			Puerto:                    "8080",
+			CORSOrigenes:              []string{"http://localhost:3000", "http://localhost", "http://localhost:80"},
+			ReadHeaderTimeoutSegundos: 5,
+			ReadTimeoutSegundos:       15,
+			WriteTimeoutSegundos:      30,
+			IdleTimeoutSegundos:       60,
+			ShutdownTimeoutSegundos:   20,
+			ProxiesConfiables:         []string{"127.0.0.1", "::1"},
+		},
+		DB: DBConfig{
+			Host:                   "localhost",
+			Puerto:                 "3306",
+			Usuario:                "root",
+			Nombre:                 "proyecto_gym_db",
+			MaxOpenConns:           25,
+			MaxIdleConns:           10,
+			ConnMaxLifetimeMinutos: 30,
+		},
+		JWT: JWTConfig{
+			Secreto:         jwtSecretoDesarrollo,
+			ExpiracionHoras: 24,
+		},
+		Log: LogConfig{
+			Nivel:   "debug",
+			Formato: "text",
+		},
+		Pagos: PagosConfig{
+			Proveedor:      "fake",
+			WebhookSecreto: "proyecto_gym_webhook_dev",
+			PrecioClase:    300000,
+		},
+		Facturacion: FacturacionConfig{
+			RazonSocial: "Proyecto Gym",
+		},
+		Cancelacion: CancelacionConfig{
+			HorasLimite:    2,
+			Modo:           "penalizar",
+			MaxFaltas:      3,
+			VentanaDias:    30,
+			SuspensionDias: 7,
+		},
+		Notificaciones: NotificacionesConfig{
+			Sender:            "archivo",
+			Directorio:        "./correos",
+			IntervaloSegundos: 5,
+			Lote:              20,
+			MaxIntentos:       8,
+			SMTP: SMTPConfig{
+				Puerto:          "587",
+				Remitente:       "Proyecto Gym <no-responder@proyecto-gym.com>",
+				TimeoutSegundos: 30,
+			},
+		},
+		Recordatorios: RecordatoriosConfig{
+			AnticipacionMinutos: 120,
+			IntervaloSegundos:   60,
+		},
+		Calendario: CalendarioConfig{
+			ZonaHoraria: "America/Argentina/Cordoba",
+		},
+		Disponibilidad: DisponibilidadConfig{
+			Broker:            "memoria",
+			HeartbeatSegundos: 25,
+		},
+		Idempotencia: IdempotenciaConfig{
+			TTLHoras: 24,
+		},
+		Limites: LimitesConfig{
+			Almacen:               "memoria",
+			LoginIPCapacidad:      20,
+			LoginIPPorMinuto:      10,
+			LoginCuentaCapacidad:  5,
+			LoginCuentaPorMinuto:  1,
+			RecuperacionCapacidad: 3,
+			RecuperacionPorMinuto: 1,
+			VerificacionCapacidad: 3,
+			VerificacionPorMinuto: 1,
+			BloqueoIntentos:       5,
+			BloqueoMinutos:        5,
+			BloqueoMaxMinutos:     24 * 60,
+		},
+		Cuentas: CuentasConfig{
+			URLFrontend:         "http://localhost:3000",
+			RecuperacionMinutos: 60,
+			VerificacionHoras:   48,
+		},
+	}
+
+	switch perfil {
+	case PerfilTest:
+		c.DB.Nombre = "proyecto_gym_test"
+		c.Log.Nivel = "warn"
+		c.Notificaciones.Directorio = os.TempDir() + "/proyecto-gym-correos"
+	case PerfilProd:
+		c.JWT.Secreto = ""
+		c.Pagos.Proveedor = ""
+		c.Pagos.WebhookSecreto = ""
+		c.Log.Nivel = "info"
+		c.Log.Formato = "json"
+		c.Notificaciones.Sender = "smtp"
+	}
+
+	return c
+}
+
+// Cargar arma la configuración a partir de los argumentos de línea de
+// comandos (-config, -perfil, -puerto) y del entorno, y la valida. Si hay
+// errores los devuelve todos juntos.
+func Cargar(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("proyecto-gym-backend", flag.ContinueOnError)
+	archivo := flags.String("config", os.Getenv("CONFIG_FILE"), "archivo de configuración JSON")
+	perfil := flags.String("perfil", "", "perfil de ejecución: dev, test o prod")
+	puerto := flags.String("puerto", "", "puerto HTTP")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *perfil == "" {
+		*perfil = os.Getenv("APP_PERFIL")
+	}
+	if *perfil == "" {
+		*perfil = PerfilDev
+	}
+
+	c := Defaults(*perfil)
+
+	if *archivo != "" {
+		contenido, err := os.ReadFile(*archivo)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer el archivo de configuración: %w", err)
+		}
+		if err := json.Unmarshal(contenido, c); err != nil {
+			return nil, fmt.Errorf("archivo de configuración inválido: %w", err)
+		}
+	}
+
+	var errs []error
+	cargarEntorno(reflect.ValueOf(c).Elem(), &errs)
+
+	c.Perfil = *perfil
+	if *puerto != "" {
+		c.Servidor.Puerto = *puerto
+	}
+
+	errs = append(errs, c.Validar()...)
+	if len(errs) > 0 {
+		return nil, &ErrorConfiguracion{Errores: errs}
+	}
+
+	return c, nil
+}
+
+// cargarEntorno recorre la configuración y sobreescribe cada campo con
+// etiqueta env si la variable de entorno correspondiente está definida.
+func cargarEntorno(v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		campo := v.Field(i)
+		if campo.Kind() == reflect.Struct {
+			cargarEntorno(campo, errs)
+			continue
+		}
+
+		nombre := t.Field(i).Tag.Get("env")
+		if nombre == "" {
+			continue
+		}
+		valor, ok := os.LookupEnv(nombre)
+		if !ok || valor == "" {
+			continue
+		}
+
+		switch campo.Kind() {
+		case reflect.String:
+			campo.SetString(valor)
+		case reflect.Int:
+			n, err := strconv.Atoi(valor)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s debe ser un número entero (valor: %q)", nombre, valor))
+				continue
+			}
+			campo.SetInt(int64(n))
+		case reflect.Slice:
+			var lista []string
+			for _, item := range strings.Split(valor, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					lista = append(lista, item)
+				}
+			}
+			campo.Set(reflect.ValueOf(lista))
+		}
+	}
+}
+
+// Validar devuelve todos los valores faltantes o inválidos de la configuración.
+func (c *Config) Validar() []error {
+	var errs []error
+	requerir := func(valor, nombre string) {
+		if strings.TrimSpace(valor) == "" {
+			errs = append(errs, fmt.Errorf("%s es obligatorio", nombre))
+		}
+	}
+	positivo := func(valor int, nombre string) {
+		if valor <= 0 {
+			errs = append(errs, fmt.Errorf("%s debe ser mayor que cero", nombre))
+		}
+	}
+	unoDe := func(valor, nombre string, opciones ...string) {
+		for _, opcion := range opciones {
+			if valor == opcion {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s debe ser uno de %v (valor: %q)", nombre, opciones, valor))
+	}
+
+	unoDe(c.Perfil, "APP_PERFIL", PerfilDev, PerfilTest, PerfilProd)
+
+	if _, err := strconv.Atoi(c.Servidor.Puerto); err != nil {
+		errs = append(errs, fmt.Errorf("PORT debe ser numérico (valor: %q)", c.Servidor.Puerto))
+	}
+	if len(c.Servidor.CORSOrigenes) == 0 {
+		errs = append(errs, errors.New("CORS_ORIGENES debe tener al menos un origen"))
+	}
+	positivo(c.Servidor.ReadHeaderTimeoutSegundos, "SERVER_READ_HEADER_TIMEOUT_SEGUNDOS")
+	positivo(c.Servidor.ReadTimeoutSegundos, "SERVER_READ_TIMEOUT_SEGUNDOS")
+	positivo(c.Servidor.WriteTimeoutSegundos, "SERVER_WRITE_TIMEOUT_SEGUNDOS")
+	positivo(c.Servidor.IdleTimeoutSegundos, "SERVER_IDLE_TIMEOUT_SEGUNDOS")
+	positivo(c.Servidor.ShutdownTimeoutSegundos, "SERVER_SHUTDOWN_TIMEOUT_SEGUNDOS")
+
+	requerir(c.DB.Host, "DB_HOST")
+	requerir(c.DB.Puerto, "DB_PORT")
+	requerir(c.DB.Usuario, "DB_USER")
+	requerir(c.DB.Nombre, "DB_NAME")
+	positivo(c.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	positivo(c.DB.ConnMaxLifetimeMinutos, "DB_CONN_MAX_LIFETIME_MINUTOS")
+	if c.DB.MaxIdleConns < 0 || c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS debe estar entre 0 y DB_MAX_OPEN_CONNS"))
+	}
+
+	requerir(c.JWT.Secreto, "JWT_SECRET")
+	positivo(c.JWT.ExpiracionHoras, "JWT_EXPIRACION_HORAS")
+
+	unoDe(c.Log.Nivel, "LOG_LEVEL", "debug", "info", "warn", "error")
+	unoDe(c.Log.Formato, "LOG_FORMAT", "json", "text")
+
+	// Sin proveedor los pagos en línea quedan deshabilitados
+	if c.Pagos.Proveedor != "" {
+		requerir(c.Pagos.WebhookSecreto, "PAGOS_WEBHOOK_SECRET")
+	}
+	positivo(c.Pagos.PrecioClase, "PAGOS_PRECIO_CLASE")
+
+	unoDe(c.Cancelacion.Modo, "CANCELACION_MODO", "penalizar", "bloquear")
+	if c.Cancelacion.HorasLimite < 0 {
+		errs = append(errs, errors.New("CANCELACION_HORAS_LIMITE no puede ser negativo"))
+	}
+	positivo(c.Cancelacion.VentanaDias, "PENALIZACION_VENTANA_DIAS")
+	positivo(c.Cancelacion.SuspensionDias, "PENALIZACION_SUSPENSION_DIAS")
+
+	unoDe(c.Notificaciones.Sender, "NOTIFICACIONES_SENDER", "archivo", "smtp")
+	if c.Notificaciones.Sender == "smtp" {
+		requerir(c.Notificaciones.SMTP.Host, "SMTP_HOST")
+		positivo(c.Notificaciones.SMTP.TimeoutSegundos, "SMTP_TIMEOUT_SEGUNDOS")
+	}
+	if c.Notificaciones.Sender == "archivo" {
+		requerir(c.Notificaciones.Directorio, "NOTIFICACIONES_DIR")
+	}
+	requerir(c.Notificaciones.SMTP.Remitente, "SMTP_REMITENTE")
+	positivo(c.Notificaciones.IntervaloSegundos, "NOTIFICACIONES_INTERVALO_SEGUNDOS")
+	positivo(c.Notificaciones.Lote, "NOTIFICACIONES_LOTE")
+	positivo(c.Notificaciones.MaxIntentos, "NOTIFICACIONES_MAX_INTENTOS")
+
+	positivo(c.Recordatorios.AnticipacionMinutos, "RECORDATORIOS_ANTICIPACION_MINUTOS")
+	positivo(c.Recordatorios.IntervaloSegundos, "RECORDATORIOS_INTERVALO_SEGUNDOS")
+
+	fecha := func(valor, nombre string) {
+		if valor == "" {
+			return
+		}
+		if _, err := time.Parse(FormatoFecha, valor); err != nil {
+			errs = append(errs, fmt.Errorf("%s debe ser una fecha AAAA-MM-DD (valor: %q)", nombre, valor))
+		}
+	}
+	fecha(c.API.AliasDeprecacion, "API_ALIAS_DEPRECACION")
+	fecha(c.API.AliasSunset, "API_ALIAS_SUNSET")
+	if c.API.AliasSunset != "" && c.API.AliasDeprecacion == "" {
+		errs = append(errs, errors.New("API_ALIAS_SUNSET requiere API_ALIAS_DEPRECACION"))
+	}
+
+	if _, err := time.LoadLocation(c.Calendario.ZonaHoraria); err != nil || c.Calendario.ZonaHoraria == "" {
+		errs = append(errs, fmt.Errorf("CALENDARIO_ZONA_HORARIA debe ser una zona horaria IANA (valor: %q)", c.Calendario.ZonaHoraria))
+	}
+
+	requerir(c.Disponibilidad.Broker, "DISPONIBILIDAD_BROKER")
+	positivo(c.Disponibilidad.HeartbeatSegundos, "DISPONIBILIDAD_HEARTBEAT_SEGUNDOS")
+	positivo(c.Idempotencia.TTLHoras, "IDEMPOTENCIA_TTL_HORAS")
+
+	unoDe(c.Limites.Almacen, "LIMITES_ALMACEN", "memoria", "sql")
+	positivo(c.Limites.LoginIPCapacidad, "LIMITE_LOGIN_IP_CAPACIDAD")
+	positivo(c.Limites.LoginIPPorMinuto, "LIMITE_LOGIN_IP_POR_MINUTO")
+	positivo(c.Limites.LoginCuentaCapacidad, "LIMITE_LOGIN_CUENTA_CAPACIDAD")
+	positivo(c.Limites.LoginCuentaPorMinuto, "LIMITE_LOGIN_CUENTA_POR_MINUTO")
+	positivo(c.Limites.RecuperacionCapacidad, "LIMITE_RECUPERACION_CAPACIDAD")
+	positivo(c.Limites.RecuperacionPorMinuto, "LIMITE_RECUPERACION_POR_MINUTO")
+	positivo(c.Limites.VerificacionCapacidad, "LIMITE_VERIFICACION_CAPACIDAD")
+	positivo(c.Limites.VerificacionPorMinuto, "LIMITE_VERIFICACION_POR_MINUTO")
+	positivo(c.Limites.BloqueoIntentos, "BLOQUEO_INTENTOS")
+	positivo(c.Limites.BloqueoMinutos, "BLOQUEO_MINUTOS")
+	if c.Limites.BloqueoMaxMinutos < c.Limites.BloqueoMinutos {
+		errs = append(errs, errors.New("BLOQUEO_MAX_MINUTOS no puede ser menor que BLOQUEO_MINUTOS"))
+	}
+
+	requerir(c.Cuentas.URLFrontend, "FRONTEND_URL")
+	positivo(c.Cuentas.RecuperacionMinutos, "RECUPERACION_EXPIRACION_MINUTOS")
+	positivo(c.Cuentas.VerificacionHoras, "VERIFICACION_EXPIRACION_HORAS")
+
+	// En producción no se aceptan los valores pensados para desarrollo
+	if c.Perfil == PerfilProd {
+		if c.JWT.Secreto == jwtSecretoDesarrollo || len(c.JWT.Secreto) < 32 {
+			errs = append(errs, errors.New("JWT_SECRET debe tener al menos 32 caracteres y no ser el de desarrollo"))
+		}
+		requerir(c.DB.Password, "DB_PASSWORD")
+		if c.Pagos.Proveedor == "fake" {
+			errs = append(errs, errors.New("PAGOS_PROVEEDOR no puede ser fake en producción"))
+		}
+	}
+
+	return errs
+}
+
+// ErrorConfiguracion agrupa todos los problemas encontrados al cargar la configuración.
+type ErrorConfiguracion struct {
+	Errores []error
+}
+
+func (e *ErrorConfiguracion) Error() string {
+	mensajes := make([]string, len(e.Errores))
+	for i, err := range e.Errores {
+		mensajes[i] = "  - " + err.Error()
+	}
+	return "configuración inválida:\n" + strings.Join(mensajes, "\n")
+}
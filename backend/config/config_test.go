@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// prodCompleto devuelve una configuración de producción con todos los secretos cargados.
+func prodCompleto() *Config {
+	c := Defaults(PerfilProd)
+	c.JWT.Secreto = strings.Repeat("s", 40)
+	c.DB.Password = "clave"
+	c.Pagos.Proveedor = "mercadopago"
+	c.Pagos.WebhookSecreto = "secreto-webhook"
+	c.Notificaciones.SMTP.Host = "smtp.gym.test"
+	return c
+}
+
+func TestValidar(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		config    func() *Config
+		esperados []string // fragmentos que deben aparecer en los errores; vacío = válida
+	}{
+		{"dev por defecto", func() *Config { return Defaults(PerfilDev) }, nil},
+		{"test por defecto", func() *Config { return Defaults(PerfilTest) }, nil},
+		{"prod completa", prodCompleto, nil},
+		{"prod por defecto exige secretos", func() *Config { return Defaults(PerfilProd) },
+			[]string{"JWT_SECRET", "DB_PASSWORD", "SMTP_HOST"}},
+		{"prod sin pasarela de pagos", func() *Config {
+			c := prodCompleto()
+			c.Pagos.Proveedor = ""
+			c.Pagos.WebhookSecreto = ""
+			return c
+		}, nil},
+		{"pasarela sin secreto del webhook", func() *Config {
+			c := prodCompleto()
+			c.Pagos.WebhookSecreto = ""
+			return c
+		}, []string{"PAGOS_WEBHOOK_SECRET"}},
+		{"prod con pasarela fake", func() *Config {
+			c := prodCompleto()
+			c.Pagos.Proveedor = "fake"
+			return c
+		}, []string{"PAGOS_PROVEEDOR no puede ser fake"}},
+		{"prod con el secreto JWT de desarrollo", func() *Config {
+			c := prodCompleto()
+			c.JWT.Secreto = jwtSecretoDesarrollo
+			return c
+		}, []string{"JWT_SECRET debe tener al menos 32 caracteres"}},
+		{"perfil desconocido", func() *Config { return Defaults("staging") }, []string{"APP_PERFIL"}},
+		{"valores fuera de rango", func() *Config {
+			c := Defaults(PerfilDev)
+			c.Servidor.Puerto = "http"
+			c.DB.MaxIdleConns = c.DB.MaxOpenConns + 1
+			c.Cancelacion.Modo = "ignorar"
+			c.Limites.BloqueoMaxMinutos = c.Limites.BloqueoMinutos - 1
+			return c
+		}, []string{"PORT", "DB_MAX_IDLE_CONNS", "CANCELACION_MODO", "BLOQUEO_MAX_MINUTOS"}},
+		{"fechas del alias", func() *Config {
+			c := Defaults(PerfilDev)
+			c.API.AliasSunset = "31/12/2025"
+			return c
+		}, []string{"API_ALIAS_SUNSET debe ser una fecha", "API_ALIAS_SUNSET requiere API_ALIAS_DEPRECACION"}},
+		{"zona horaria inválida", func() *Config {
+			c := Defaults(PerfilDev)
+			c.Calendario.ZonaHoraria = "Marte/Olympus"
+			return c
+		}, []string{"CALENDARIO_ZONA_HORARIA"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			errs := caso.config().Validar()
+			mensaje := errors.Join(errs...)
+			if len(caso.esperados) == 0 {
+				if len(errs) > 0 {
+					t.Fatalf("se esperaba una configuración válida:\n%v", mensaje)
+				}
+				return
+			}
+			for _, esperado := range caso.esperados {
+				if mensaje == nil || !strings.Contains(mensaje.Error(), esperado) {
+					t.Errorf("falta un error con %q en:\n%v", esperado, mensaje)
+				}
+			}
+		})
+	}
+}
+
+func TestCargar(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("APP_PERFIL", "")
+	t.Setenv("PORT", "9000")
+	t.Setenv("CORS_ORIGENES", "https://gym.test, https://admin.gym.test")
+	t.Setenv("JWT_EXPIRACION_HORAS", "12")
+
+	c, err := Cargar([]string{"-puerto", "9090"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Perfil != PerfilDev {
+		t.Errorf("Perfil = %q, se esperaba %q", c.Perfil, PerfilDev)
+	}
+	if c.Servidor.Puerto != "9090" {
+		t.Errorf("Puerto = %q, el flag debería tener prioridad sobre PORT", c.Servidor.Puerto)
+	}
+	if strings.Join(c.Servidor.CORSOrigenes, "|") != "https://gym.test|https://admin.gym.test" {
+		t.Errorf("CORSOrigenes = %v", c.Servidor.CORSOrigenes)
+	}
+	if c.JWT.ExpiracionHoras != 12 {
+		t.Errorf("ExpiracionHoras = %d, se esperaba 12", c.JWT.ExpiracionHoras)
+	}
+}
+
+func TestCargarAcumulaErrores(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("JWT_EXPIRACION_HORAS", "doce")
+	t.Setenv("PAGOS_PROVEEDOR", "fake")
+
+	_, err := Cargar([]string{"-perfil", PerfilProd})
+	var errConfig *ErrorConfiguracion
+	if !errors.As(err, &errConfig) {
+		t.Fatalf("err = %v, se esperaba *ErrorConfiguracion", err)
+	}
+	for _, esperado := range []string{"JWT_EXPIRACION_HORAS debe ser un número entero", "JWT_SECRET", "PAGOS_PROVEEDOR no puede ser fake"} {
+		if !strings.Contains(err.Error(), esperado) {
+			t.Errorf("falta %q en:\n%v", esperado, err)
+		}
+	}
+}
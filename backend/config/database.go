@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"sync/atomic"
+	"time"
+
+	"proyecto-gym-backend/logger"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -11,28 +15,45 @@ import (
 
 var DB *gorm.DB
 
+var migracionCompleta atomic.Bool
+
 func InitDB() {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "3306")
-	user := getEnv("DB_USER", "root")
-	password := getEnv("DB_PASSWORD", "")
-	dbname := getEnv("DB_NAME", "proyecto_gym_db")
+	cfg := App.DB
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		user, password, host, port, dbname)
+		cfg.Usuario, cfg.Password, cfg.Host, cfg.Puerto, cfg.Nombre)
 
 	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.NewGormLogger()})
 	if err != nil {
-		log.Fatal("❌ Error conectando a la base de datos:", err)
+		slog.Error("error conectando a la base de datos", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("✅ Conexión a base de datos Proyecto-gym establecida")
+	// Configurar pool de conexiones
+	sqlDB, err := DB.DB()
+	if err != nil {
+		slog.Error("error obteniendo el pool de conexiones", "error", err)
+		os.Exit(1)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutos) * time.Minute)
+
+	slog.Info("conexión a base de datos establecida", "host", cfg.Host, "base", cfg.Nombre)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Migrar ejecuta las migraciones automáticas y recuerda si terminaron bien,
+// para que el endpoint de readiness pueda informarlo.
+func Migrar(modelos ...interface{}) error {
+	if err := DB.AutoMigrate(modelos...); err != nil {
+		migracionCompleta.Store(false)
+		return err
 	}
-	return defaultValue
+	migracionCompleta.Store(true)
+	return nil
+}
+
+func MigracionCompleta() bool {
+	return migracionCompleta.Load()
 }
@@ -1,14 +1,19 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"proyecto-gym-backend/apperrors"
 	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/i18n"
+	"proyecto-gym-backend/logger"
 	"proyecto-gym-backend/models"
 	"proyecto-gym-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func GetActividades(c *gin.Context) {
@@ -34,7 +39,7 @@ func GetActividades(c *gin.Context) {
 
 	var actividades []models.Actividad
 	if err := query.Find(&actividades).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo actividades"})
+		c.Error(err)
 		return
 	}
 
@@ -90,7 +95,7 @@ func GetActividadesAdmin(c *gin.Context) {
 
 	var actividades []models.Actividad
 	if err := query.Find(&actividades).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo actividades"})
+		c.Error(err)
 		return
 	}
 
@@ -107,13 +112,13 @@ func GetActividadesAdmin(c *gin.Context) {
 func GetActividadByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(apperrors.ErrIDInvalido)
 		return
 	}
 
 	actividad, err := services.GetActividadByIDConCupo(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
+		c.Error(err)
 		return
 	}
 
@@ -123,12 +128,12 @@ func GetActividadByID(c *gin.Context) {
 func CreateActividad(c *gin.Context) {
 	var actividad models.Actividad
 	if err := c.ShouldBindJSON(&actividad); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if err := config.DB.Create(&actividad).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando actividad"})
+		c.Error(err)
 		return
 	}
 
@@ -138,40 +143,162 @@ func CreateActividad(c *gin.Context) {
 func UpdateActividad(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(apperrors.ErrIDInvalido)
 		return
 	}
 
 	var actividad models.Actividad
 	if err := config.DB.First(&actividad, uint(id)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Actividad no encontrada"})
+		c.Error(services.ErrActividadNoEncontrada)
 		return
 	}
 
 	if err := c.ShouldBindJSON(&actividad); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	if err := config.DB.Save(&actividad).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error actualizando actividad"})
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&actividad).Error; err != nil {
+			return err
+		}
+		return services.NotificarCambioActividad(tx, &actividad, false)
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	// Un cambio de cupo máximo también cambia el cupo disponible
+	services.PublicarCupo(actividad.ID)
 	c.JSON(http.StatusOK, actividad)
 }
 
 func DeleteActividad(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var actividad models.Actividad
+	if err := config.DB.First(&actividad, uint(id)).Error; err != nil {
+		c.Error(services.ErrActividadNoEncontrada)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.NotificarCambioActividad(tx, &actividad, true); err != nil {
+			return err
+		}
+		return tx.Delete(&actividad).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.Idioma(c), "ACTIVIDAD_ELIMINADA", nil)})
+}
+
+// ImportActividades carga actividades desde un archivo CSV o XLSX enviado en
+// el campo "archivo". Con simular=true sólo valida y cuenta los cambios.
+func ImportActividades(c *gin.Context) {
+	archivo, err := c.FormFile("archivo")
+	if err != nil {
+		c.Error(apperrors.ErrDatosInvalidos.ConCausa(err))
+		return
+	}
+
+	formato, err := services.FormatoArchivo(archivo.Filename)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	contenido, err := archivo.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer contenido.Close()
+
+	simular, _ := strconv.ParseBool(c.Query("simular"))
+	resultado, err := services.ImportarActividades(contenido, formato, simular)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	idioma := i18n.Idioma(c)
+	for i := range resultado.Errores {
+		resultado.Errores[i].Campos = apperrors.LocalizarCampos(resultado.Errores[i].Campos, idioma)
+	}
+
+	logger.FromContext(c.Request.Context()).Info("importación de actividades",
+		"archivo", archivo.Filename, "simulacion", simular, "aplicada", resultado.Aplicada,
+		"creadas", resultado.Creadas, "actualizadas", resultado.Actualizadas, "errores", len(resultado.Errores))
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// ExportActividades descarga todas las actividades con las columnas que acepta la importación.
+func ExportActividades(c *gin.Context) {
+	formato := c.DefaultQuery("formato", services.FormatoCSV)
+	contenido, err := services.ExportarActividades(formato)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tipo := "text/csv; charset=utf-8"
+	if formato == services.FormatoXLSX {
+		tipo = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="actividades.%s"`, formato))
+	c.Data(http.StatusOK, tipo, contenido)
+}
+
+// GetHorario devuelve el cronograma como grilla semanal. Si la petición trae
+// token marca las clases en las que el usuario está inscrito.
+func GetHorario(c *gin.Context) {
+	filtro := services.FiltroHorario{
+		Categoria: c.Query("categoria"),
+		Profesor:  c.Query("profesor"),
+		Sala:      c.Query("sala"),
+	}
+
+	horario, err := services.GetHorarioSemanal(filtro, c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, horario)
+}
+
+// GetHorarioPDF descarga el cronograma semanal para imprimir. Responde 304
+// si el cliente ya tiene la versión vigente.
+func GetHorarioPDF(c *gin.Context) {
+	filtro := services.FiltroHorario{
+		Categoria: c.Query("categoria"),
+		Profesor:  c.Query("profesor"),
+		Sala:      c.Query("sala"),
+	}
+
+	contenido, etag, err := services.HorarioPDF(filtro)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := config.DB.Delete(&models.Actividad{}, uint(id)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando actividad"})
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Actividad eliminada correctamente"})
+	c.Header("Content-Disposition", `inline; filename="cronograma.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", contenido)
 }
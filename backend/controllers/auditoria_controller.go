@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetAuditoriaAdmin(c *gin.Context) {
+	paginacion, err := leerPaginacion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filtro := services.FiltroAuditoria{
+		Tipo:      c.Query("tipo"),
+		Pagina:    paginacion.Pagina,
+		PorPagina: paginacion.PorPagina,
+	}
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		id, err := strconv.ParseUint(usuarioID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.ErrIDInvalido)
+			return
+		}
+		filtro.UsuarioID = uint(id)
+	}
+
+	eventos, total, err := services.ListarAuditoria(filtro)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Pagina{
+		Datos:     eventos,
+		Total:     total,
+		Pagina:    paginacion.Pagina,
+		PorPagina: paginacion.PorPagina,
+	})
+}
@@ -1,13 +1,20 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
 
 	"proyecto-gym-backend/config"
 	"proyecto-gym-backend/models"
 	"proyecto-gym-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type LoginRequest struct {
@@ -30,34 +37,33 @@ type LoginResponse struct {
 func Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	var user models.Usuario
-	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
-		return
-	}
-
-	// Verificar contraseña (primero SHA256, luego MD5 como fallback)
-	passwordHashSHA := services.HashPasswordSHA256(req.Password)
-	passwordHashMD5 := services.HashPasswordMD5(req.Password)
-
-	if user.PasswordHash != passwordHashSHA && user.PasswordHash != passwordHashMD5 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
+	user, err := services.IniciarSesion(req.Email, req.Password, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrCuentaBloqueada) {
+			var appErr *apperrors.Error
+			if errors.As(err, &appErr) {
+				if hasta, ok := appErr.Detalles["bloqueada_hasta"].(time.Time); ok {
+					c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(hasta).Seconds()))))
+				}
+			}
+		}
+		c.Error(err)
 		return
 	}
 
-	token, err := services.GenerateJWT(user)
+	token, err := services.GenerateJWT(*user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generando token"})
+		c.Error(err)
 		return
 	}
 
 	response := LoginResponse{
 		Token: token,
-		User:  user,
+		User:  *user,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -66,14 +72,14 @@ func Login(c *gin.Context) {
 func Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	// Verificar que el email no esté en uso
+	// Verificar que el email no esté en uso, tampoco por una cuenta eliminada
 	var existingUser models.Usuario
-	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "El email ya está registrado"})
+	if err := config.DB.Unscoped().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		c.Error(services.ErrEmailRegistrado)
 		return
 	}
 
@@ -95,15 +101,22 @@ func Register(c *gin.Context) {
 		Tipo:         req.Tipo,
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando usuario"})
+	// El correo de verificación sólo se encola si el alta se confirma
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return services.EncolarVerificacionEmail(tx, user.ID)
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	// Generar token JWT para login automático
 	token, err := services.GenerateJWT(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Usuario creado pero error generando token"})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const tipoCalendario = "text/calendar; charset=utf-8"
+
+// GetCalendarioUsuario devuelve la URL secreta del feed iCal del usuario.
+func GetCalendarioUsuario(c *gin.Context) {
+	responderURLCalendario(c, false)
+}
+
+// RegenerarCalendarioUsuario invalida la URL anterior del feed y devuelve una nueva.
+func RegenerarCalendarioUsuario(c *gin.Context) {
+	responderURLCalendario(c, true)
+}
+
+func responderURLCalendario(c *gin.Context, regenerar bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	token, err := services.TokenCalendario(uint(userID), regenerar)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": urlBaseCalendario(c) + "/api/v1/calendario/socios/" + token + ".ics"})
+}
+
+// urlBaseCalendario es la URL pública del backend: la configurada o, si no
+// hay, la del host al que llegó la petición.
+func urlBaseCalendario(c *gin.Context) string {
+	if config.App.Calendario.URLBase != "" {
+		return strings.TrimSuffix(config.App.Calendario.URLBase, "/")
+	}
+	esquema := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		esquema = "https"
+	}
+	return esquema + "://" + c.Request.Host
+}
+
+// GetCalendarioSocio sirve el feed iCal de un socio. El token de la URL es la
+// única autenticación, para que los clientes de calendario puedan suscribirse.
+func GetCalendarioSocio(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ical, err := services.CalendarioUsuario(token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="mis-clases.ics"`)
+	c.Data(http.StatusOK, tipoCalendario, ical)
+}
+
+// GetCalendarioPublico sirve el feed iCal del cronograma completo.
+func GetCalendarioPublico(c *gin.Context) {
+	ical, err := services.CalendarioPublico(c.Query("categoria"), c.Query("profesor"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="cronograma.ics"`)
+	c.Data(http.StatusOK, tipoCalendario, ical)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/logger"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamDisponibilidad abre un stream SSE con los cambios de cupo. Con
+// actividades=1,2,3 sólo se envían los de esas actividades. Al conectarse se
+// manda el cupo actual para que el cliente no dependa de un listado previo.
+func StreamDisponibilidad(c *gin.Context) {
+	var ids []uint
+	filtro := map[uint]bool{}
+	if valor := c.Query("actividades"); valor != "" {
+		for _, parte := range strings.Split(valor, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(parte), 10, 32)
+			if err != nil {
+				c.Error(apperrors.ErrIDInvalido.ConDetalle("actividades", valor))
+				return
+			}
+			ids = append(ids, uint(id))
+			filtro[uint(id)] = true
+		}
+	}
+
+	// Suscribirse antes de leer el estado inicial para no perder cambios
+	eventos, desuscribir := services.SuscribirCupos()
+	defer desuscribir()
+
+	iniciales, err := services.CuposActividades(ids)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// El stream dura más que el WriteTimeout del servidor
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("no se pudo quitar el timeout de escritura", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx no debe acumular los eventos
+
+	metrics.StreamsDisponibilidad.Inc()
+	defer metrics.StreamsDisponibilidad.Dec()
+
+	heartbeat := time.NewTicker(time.Duration(config.App.Disponibilidad.HeartbeatSegundos) * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		if iniciales != nil {
+			for _, evento := range iniciales {
+				c.SSEvent("cupo", evento)
+			}
+			iniciales = nil
+			return true
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case evento, ok := <-eventos:
+			if !ok {
+				return false
+			}
+			if len(filtro) == 0 || filtro[evento.ActividadID] {
+				c.SSEvent("cupo", evento)
+			}
+			return true
+		case <-heartbeat.C:
+			// Comentario SSE: mantiene viva la conexión a través de proxies
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"proyecto-gym-backend/docs"
+
+	"github.com/gin-gonic/gin"
+)
+
+const paginaDocs = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <title>API Gimnasio</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+// GetOpenAPI devuelve la especificación OpenAPI de la API.
+func GetOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.OpenAPI)
+}
+
+// GetDocs sirve la documentación interactiva (Swagger UI) de la especificación.
+func GetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(paginaDocs))
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnularFacturaRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+func GetFacturasUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var facturas []models.Factura
+	if err := config.DB.Where("usuario_id = ?", uint(userID)).
+		Order("secuencia DESC").Find(&facturas).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, facturas)
+}
+
+func GetFacturaUsuarioPDF(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	facturaID, err := strconv.ParseUint(c.Param("facturaId"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var factura models.Factura
+	if err := config.DB.Preload("Usuario").
+		Where("usuario_id = ?", uint(userID)).First(&factura, uint(facturaID)).Error; err != nil {
+		c.Error(services.ErrFacturaNoEncontrada)
+		return
+	}
+
+	enviarFacturaPDF(c, &factura)
+}
+
+func GetFacturasAdmin(c *gin.Context) {
+	query := config.DB.Preload("Usuario")
+
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		query = query.Where("usuario_id = ?", usuarioID)
+	}
+
+	var facturas []models.Factura
+	if err := query.Order("secuencia DESC").Find(&facturas).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, facturas)
+}
+
+func GetFacturaAdminPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var factura models.Factura
+	if err := config.DB.Preload("Usuario").First(&factura, uint(id)).Error; err != nil {
+		c.Error(services.ErrFacturaNoEncontrada)
+		return
+	}
+
+	enviarFacturaPDF(c, &factura)
+}
+
+func AnularFactura(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req AnularFacturaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	factura, err := services.AnularFactura(uint(id), req.Motivo)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, factura)
+}
+
+func enviarFacturaPDF(c *gin.Context, factura *models.Factura) {
+	contenido, err := services.RenderFacturaPDF(factura)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="factura-%s.pdf"`, factura.Numero))
+	c.Data(http.StatusOK, "application/pdf", contenido)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"proyecto-gym-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+var apagando atomic.Bool
+
+// MarcarApagado hace que /readyz responda 503 mientras el servidor drena
+// las peticiones en curso, para que el balanceador deje de enviar tráfico.
+func MarcarApagado() {
+	apagando.Store(true)
+}
+
+// Healthz indica que el proceso está vivo.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz indica si la instancia puede recibir tráfico: base de datos
+// accesible, migraciones aplicadas y sin apagado en curso.
+func Readyz(c *gin.Context) {
+	checks := gin.H{}
+	listo := true
+
+	if apagando.Load() {
+		checks["servidor"] = "apagando"
+		listo = false
+	} else {
+		checks["servidor"] = "ok"
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	sqlDB, err := config.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		checks["base_de_datos"] = "sin conexión"
+		listo = false
+	} else {
+		checks["base_de_datos"] = "ok"
+	}
+
+	if config.MigracionCompleta() {
+		checks["migraciones"] = "ok"
+	} else {
+		checks["migraciones"] = "pendientes"
+		listo = false
+	}
+
+	status := http.StatusOK
+	estado := "ok"
+	if !listo {
+		status = http.StatusServiceUnavailable
+		estado = "no disponible"
+	}
+
+	c.JSON(status, gin.H{"status": estado, "checks": checks})
+}
@@ -1,83 +1,116 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"proyecto-gym-backend/apperrors"
 	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/i18n"
+	"proyecto-gym-backend/logger"
+	"proyecto-gym-backend/metrics"
 	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// InscripcionRequest inscribe al usuario del token. Los administradores
+// inscriben a otros socios con CreateInscripcionAdmin.
 type InscripcionRequest struct {
-	UsuarioID   uint `json:"usuario_id" binding:"required"`
 	ActividadID uint `json:"actividad_id" binding:"required"`
 }
 
 func CreateInscripcion(c *gin.Context) {
 	var req InscripcionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Datos inválidos: %v", err.Error())})
+		c.Error(err)
 		return
 	}
 
-	// Log para debug
-	fmt.Printf("🔍 Intentando crear inscripción - Usuario ID: %d, Actividad ID: %d\n", req.UsuarioID, req.ActividadID)
+	usuarioID := c.GetUint("user_id")
+	log := logger.FromContext(c.Request.Context()).With("usuario_id", usuarioID, "actividad_id", req.ActividadID)
+	log.Debug("creando inscripción")
 
 	// Verificar que el usuario existe
 	var usuario models.Usuario
-	if err := config.DB.First(&usuario, req.UsuarioID).Error; err != nil {
-		fmt.Printf("❌ Usuario no encontrado: ID %d\n", req.UsuarioID)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Usuario con ID %d no encontrado", req.UsuarioID)})
+	if err := config.DB.First(&usuario, usuarioID).Error; err != nil {
+		log.Info("usuario no encontrado")
+		c.Error(services.ErrUsuarioNoEncontrado.ConDetalle("usuario_id", usuarioID))
+		return
+	}
+
+	if usuario.SuspendidoAt != nil {
+		c.Error(services.ErrCuentaSuspendida)
+		return
+	}
+
+	if usuario.EmailVerificadoAt == nil {
+		c.Error(services.ErrEmailNoVerificado)
+		return
+	}
+
+	// Verificar que no tenga las reservas suspendidas por penalizaciones
+	if hasta, suspendido := services.SuspensionVigente(usuarioID); suspendido {
+		c.Error(services.ErrReservasSuspendidas.ConDetalle("suspension_hasta", hasta))
 		return
 	}
-	fmt.Printf("✅ Usuario encontrado: %s (%s)\n", usuario.Nombre, usuario.Email)
 
 	// Verificar que la actividad existe
 	var actividad models.Actividad
 	if err := config.DB.First(&actividad, req.ActividadID).Error; err != nil {
-		fmt.Printf("❌ Actividad no encontrada: ID %d\n", req.ActividadID)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Actividad con ID %d no encontrada", req.ActividadID)})
+		log.Info("actividad no encontrada")
+		c.Error(services.ErrActividadNoEncontrada.ConDetalle("actividad_id", req.ActividadID))
 		return
 	}
-	fmt.Printf("✅ Actividad encontrada: %s\n", actividad.Titulo)
 
 	// Verificar que no esté ya inscrito
 	var existeInscripcion models.Inscripcion
-	if err := config.DB.Where("usuario_id = ? AND actividad_id = ?", req.UsuarioID, req.ActividadID).First(&existeInscripcion).Error; err == nil {
-		fmt.Printf("⚠️ Usuario ya inscrito en esta actividad\n")
-		c.JSON(http.StatusConflict, gin.H{"error": "Ya estás inscrito en esta actividad"})
+	if err := config.DB.Where("usuario_id = ? AND actividad_id = ?", usuarioID, req.ActividadID).First(&existeInscripcion).Error; err == nil {
+		log.Info("usuario ya inscrito en la actividad")
+		c.Error(services.ErrInscripcionRepetida)
 		return
 	}
 
 	// Verificar cupo disponible
 	var inscripcionesCount int64
 	config.DB.Model(&models.Inscripcion{}).Where("actividad_id = ?", req.ActividadID).Count(&inscripcionesCount)
-	fmt.Printf("📊 Inscripciones actuales: %d / %d\n", inscripcionesCount, actividad.CupoMaximo)
+	log.Debug("cupo de la actividad", "inscriptos", inscripcionesCount, "cupo_maximo", actividad.CupoMaximo)
 
 	if int(inscripcionesCount) >= actividad.CupoMaximo {
-		c.JSON(http.StatusConflict, gin.H{"error": "No hay cupo disponible para esta actividad"})
+		c.Error(services.ErrActividadSinCupo)
 		return
 	}
 
 	// Crear inscripción con fecha actual usando puntero
 	now := time.Now()
 	inscripcion := models.Inscripcion{
-		UsuarioID:        req.UsuarioID,
+		UsuarioID:        usuarioID,
 		ActividadID:      req.ActividadID,
 		FechaInscripcion: &now,
 	}
 
-	if err := config.DB.Create(&inscripcion).Error; err != nil {
-		fmt.Printf("❌ Error creando inscripción en BD: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error creando inscripción: %v", err.Error())})
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&inscripcion).Error; err != nil {
+			return err
+		}
+		return services.EncolarNotificacion(tx, usuario.ID, services.PlantillaInscripcionConfirmada, map[string]interface{}{
+			"Actividad": actividad,
+		})
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	fmt.Printf("✅ Inscripción creada exitosamente - ID: %d\n", inscripcion.ID)
+	log.Info("inscripción creada", "inscripcion_id", inscripcion.ID)
+	metrics.InscripcionesCreadas.Inc()
+	services.PublicarCupo(inscripcion.ActividadID)
 
 	// Cargar relaciones
 	config.DB.Preload("Usuario").Preload("Actividad").First(&inscripcion, inscripcion.ID)
@@ -88,20 +121,16 @@ func CreateInscripcion(c *gin.Context) {
 func GetInscripcionesUsuario(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuario inválido"})
+		c.Error(apperrors.ErrIDInvalido)
 		return
 	}
 
-	fmt.Printf("🔍 Obteniendo inscripciones para usuario ID: %d\n", userID)
-
 	var inscripciones []models.Inscripcion
 	if err := config.DB.Preload("Actividad").Where("usuario_id = ?", uint(userID)).Find(&inscripciones).Error; err != nil {
-		fmt.Printf("❌ Error obteniendo inscripciones: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error obteniendo inscripciones"})
+		c.Error(err)
 		return
 	}
 
-	fmt.Printf("✅ Encontradas %d inscripciones\n", len(inscripciones))
 	c.JSON(http.StatusOK, inscripciones)
 }
 
@@ -109,35 +138,211 @@ func GetInscripcionesUsuario(c *gin.Context) {
 func DeleteInscripcion(c *gin.Context) {
 	inscripcionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de inscripción inválido"})
+		c.Error(apperrors.ErrIDInvalido)
 		return
 	}
 
-	fmt.Printf("🔍 Intentando eliminar inscripción ID: %d\n", inscripcionID)
+	log := logger.FromContext(c.Request.Context()).With("inscripcion_id", inscripcionID)
 
 	// Verificar que la inscripción existe
 	var inscripcion models.Inscripcion
 	if err := config.DB.Preload("Actividad").First(&inscripcion, uint(inscripcionID)).Error; err != nil {
-		fmt.Printf("❌ Inscripción no encontrada: ID %d\n", inscripcionID)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Inscripción no encontrada"})
+		log.Info("inscripción no encontrada")
+		c.Error(services.ErrInscripcionNoEncontrada)
 		return
 	}
 
-	actividadTitulo := "Actividad desconocida"
+	// Sólo el socio inscrito (o un administrador) puede darla de baja
+	if !puedeAccederUsuario(c, inscripcion.UsuarioID) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	idioma := i18n.Idioma(c)
+	actividadTitulo := i18n.T(idioma, "ACTIVIDAD_DESCONOCIDA", nil)
 	if inscripcion.Actividad.Titulo != "" {
 		actividadTitulo = inscripcion.Actividad.Titulo
 	}
 
-	// Eliminar la inscripción
-	if err := config.DB.Delete(&inscripcion).Error; err != nil {
-		fmt.Printf("❌ Error eliminando inscripción: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error eliminando inscripción"})
+	// Eliminar la inscripción aplicando la política de cancelación
+	penalizacion, err := services.CancelarInscripcion(&inscripcion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	log.Info("inscripción eliminada", "usuario_id", inscripcion.UsuarioID, "actividad_id", inscripcion.ActividadID,
+		"penalizada", penalizacion != nil)
+
+	response := gin.H{
+		"message": i18n.T(idioma, "BAJA_INSCRIPCION", map[string]interface{}{"actividad": actividadTitulo}),
+	}
+	if penalizacion != nil {
+		response["penalizacion"] = penalizacion
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteInscripcionAdmin da de baja una inscripción sin aplicar la política de cancelación.
+func DeleteInscripcionAdmin(c *gin.Context) {
+	inscripcionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
 		return
 	}
 
-	fmt.Printf("✅ Inscripción eliminada exitosamente - Actividad: %s\n", actividadTitulo)
+	var inscripcion models.Inscripcion
+	if err := config.DB.Preload("Actividad").First(&inscripcion, uint(inscripcionID)).Error; err != nil {
+		c.Error(services.ErrInscripcionNoEncontrada)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Te has dado de baja de '%s' exitosamente", actividadTitulo),
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&inscripcion).Error; err != nil {
+			return err
+		}
+		return services.EncolarNotificacion(tx, inscripcion.UsuarioID, services.PlantillaInscripcionCancelada, map[string]interface{}{
+			"Actividad": inscripcion.Actividad,
+		})
 	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	metrics.InscripcionesCanceladas.WithLabelValues(metrics.CancelacionAdmin).Inc()
+	services.PublicarCupo(inscripcion.ActividadID)
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.Idioma(c), "INSCRIPCION_ELIMINADA", nil)})
+}
+
+// MarcarAusencia registra que el socio no se presentó a la última clase.
+func MarcarAusencia(c *gin.Context) {
+	inscripcionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var inscripcion models.Inscripcion
+	if err := config.DB.Preload("Actividad").First(&inscripcion, uint(inscripcionID)).Error; err != nil {
+		c.Error(services.ErrInscripcionNoEncontrada)
+		return
+	}
+
+	penalizacion, err := services.RegistrarAusencia(&inscripcion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, penalizacion)
+}
+
+// GetInscripcionesActividadAdmin lista los inscritos de una actividad con sus datos de contacto.
+func GetInscripcionesActividadAdmin(c *gin.Context) {
+	actividadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	inscripciones, err := services.GetInscripcionesActividad(uint(actividadID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inscripciones)
+}
+
+// CreateInscripcionAdmin inscribe a un socio desde recepción, pudiendo superar el cupo.
+func CreateInscripcionAdmin(c *gin.Context) {
+	actividadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req services.InscripcionAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	inscripcion, err := services.InscribirComoAdmin(c.GetUint("user_id"), uint(actividadID), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Info("inscripción creada por administrador",
+		"inscripcion_id", inscripcion.ID, "usuario_id", inscripcion.UsuarioID, "actividad_id", inscripcion.ActividadID,
+		"sobrecupo", inscripcion.MotivoSobrecupo != "")
+
+	c.JSON(http.StatusCreated, inscripcion)
+}
+
+// MoverInscripcionAdmin cambia a un socio de actividad en una sola operación.
+func MoverInscripcionAdmin(c *gin.Context) {
+	inscripcionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req services.MoverInscripcionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	inscripcion, err := services.MoverInscripcion(c.GetUint("user_id"), uint(inscripcionID), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inscripcion)
+}
+
+// ExportarPlanillaActividad descarga la lista de inscritos de una actividad en CSV.
+func ExportarPlanillaActividad(c *gin.Context) {
+	actividadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	inscripciones, err := services.GetInscripcionesActividad(uint(actividadID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"inscripcion_id", "usuario_id", "nombre", "email", "fecha_inscripcion", "motivo_sobrecupo"})
+	for _, inscripcion := range inscripciones {
+		fecha := ""
+		if inscripcion.FechaInscripcion != nil {
+			fecha = inscripcion.FechaInscripcion.Format("2006-01-02 15:04")
+		}
+		w.Write([]string{
+			strconv.FormatUint(uint64(inscripcion.ID), 10),
+			strconv.FormatUint(uint64(inscripcion.UsuarioID), 10),
+			inscripcion.Usuario.Nombre,
+			inscripcion.Usuario.Email,
+			fecha,
+			inscripcion.MotivoSobrecupo,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="planilla-actividad-%d.csv"`, actividadID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
 }
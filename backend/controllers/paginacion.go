@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const porPaginaDefecto = 20
+
+type PaginacionQuery struct {
+	Pagina    int `form:"pagina" json:"pagina" binding:"omitempty,min=1"`
+	PorPagina int `form:"por_pagina" json:"por_pagina" binding:"omitempty,min=1,max=100"`
+}
+
+// Pagina es la respuesta de los listados paginados.
+type Pagina struct {
+	Datos     interface{} `json:"datos"`
+	Total     int64       `json:"total"`
+	Pagina    int         `json:"pagina"`
+	PorPagina int         `json:"por_pagina"`
+}
+
+// leerPaginacion toma pagina y por_pagina de la query, con sus valores por defecto.
+func leerPaginacion(c *gin.Context) (PaginacionQuery, error) {
+	var q PaginacionQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		return q, err
+	}
+	if q.Pagina == 0 {
+		q.Pagina = 1
+	}
+	if q.PorPagina == 0 {
+		q.PorPagina = porPaginaDefecto
+	}
+	return q, nil
+}
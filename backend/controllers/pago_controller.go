@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// puedeAccederUsuario verifica que el usuario autenticado sea el dueño del
+// recurso o un administrador.
+func puedeAccederUsuario(c *gin.Context, usuarioID uint) bool {
+	if c.GetString("user_tipo") == "administrador" {
+		return true
+	}
+	return c.GetUint("user_id") == usuarioID
+}
+
+func GetPlanes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"planes":       services.Planes,
+		"precio_clase": services.PrecioClaseSuelta(),
+	})
+}
+
+func CreatePago(c *gin.Context) {
+	var req services.CrearPagoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	pago, err := services.CrearPago(c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pago)
+}
+
+func GetPagosUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var pagos []models.Pago
+	if err := config.DB.Preload("Actividad").Preload("Suscripcion").
+		Where("usuario_id = ?", uint(userID)).Order("created_at DESC").Find(&pagos).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pagos)
+}
+
+func GetPagosAdmin(c *gin.Context) {
+	query := config.DB.Preload("Usuario").Preload("Actividad").Preload("Suscripcion")
+
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	var pagos []models.Pago
+	if err := query.Order("created_at DESC").Find(&pagos).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pagos)
+}
+
+// PagoWebhook recibe las notificaciones firmadas de la pasarela de pagos.
+func PagoWebhook(c *gin.Context) {
+	proveedor, err := services.GetProveedorPagos()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperrors.ErrDatosInvalidos.ConCausa(err))
+		return
+	}
+
+	evento, err := proveedor.ParsearWebhook(payload, c.GetHeader("X-Firma"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pago, err := services.ProcesarEventoPago(proveedor.Nombre(), evento)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pago)
+}
+
+// FakeCheckout simula que el socio completa el pago en la pasarela fake:
+// arma el webhook firmado y lo procesa como si lo hubiera enviado el proveedor.
+func FakeCheckout(c *gin.Context) {
+	actual, _ := services.GetProveedorPagos()
+	proveedor, ok := actual.(*services.ProveedorFake)
+	if !ok {
+		c.Error(services.ErrPasarelaFakeDeshabilitada)
+		return
+	}
+
+	resultado := c.DefaultQuery("resultado", models.PagoAprobado)
+	payload := []byte(fmt.Sprintf(`{"referencia":%q,"estado":%q}`, c.Param("referencia"), resultado))
+
+	evento, err := proveedor.ParsearWebhook(payload, proveedor.Firmar(payload))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pago, err := services.ProcesarEventoPago(proveedor.Nombre(), evento)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pago)
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+
+	"proyecto-gym-backend/i18n"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OlvidePasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type RestablecerPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type CambiarPasswordRequest struct {
+	PasswordActual string `json:"password_actual" binding:"required"`
+	PasswordNueva  string `json:"password_nueva" binding:"required,min=6"`
+}
+
+// OlvidePassword responde siempre lo mismo, exista o no la cuenta.
+func OlvidePassword(c *gin.Context) {
+	var req OlvidePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := services.SolicitarRecuperacion(req.Email, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": i18n.T(i18n.Idioma(c), "RECUPERACION_SOLICITADA", nil)})
+}
+
+func RestablecerPassword(c *gin.Context) {
+	var req RestablecerPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := services.RestablecerPassword(req.Token, req.Password, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.Idioma(c), "PASSWORD_RESTABLECIDA", nil)})
+}
+
+// CambiarPassword devuelve un token nuevo: el que se usó para la petición
+// deja de valer junto con las demás sesiones.
+func CambiarPassword(c *gin.Context) {
+	var req CambiarPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	usuario, err := services.CambiarPassword(c.GetUint("user_id"), req.PasswordActual, req.PasswordNueva, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := services.GenerateJWT(*usuario)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token: token,
+		User:  *usuario,
+	})
+}
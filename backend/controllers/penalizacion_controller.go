@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnularPenalizacionRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+func GetPoliticaCancelacion(c *gin.Context) {
+	c.JSON(http.StatusOK, services.GetPoliticaCancelacion())
+}
+
+func GetPenalizacionesUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var penalizaciones []models.Penalizacion
+	if err := config.DB.Preload("Actividad").Where("usuario_id = ?", uint(userID)).
+		Order("created_at DESC").Find(&penalizaciones).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, penalizaciones)
+}
+
+func GetPenalizacionesAdmin(c *gin.Context) {
+	query := config.DB.Preload("Usuario").Preload("Actividad")
+
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		query = query.Where("usuario_id = ?", usuarioID)
+	}
+
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	var penalizaciones []models.Penalizacion
+	if err := query.Order("created_at DESC").Find(&penalizaciones).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, penalizaciones)
+}
+
+func AnularPenalizacion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req AnularPenalizacionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	penalizacion, err := services.AnularPenalizacion(uint(id), c.GetUint("user_id"), req.Motivo)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, penalizacion)
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdiomaRequest struct {
+	Idioma string `json:"idioma"`
+}
+
+type PreferenciaRequest struct {
+	Canal         string `json:"canal" binding:"required"`
+	Recordatorios *bool  `json:"recordatorios" binding:"required"`
+}
+
+func GetPreferenciasUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.GetPreferenciasNotificacion(uint(userID)))
+}
+
+func UpdatePreferenciaUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var req PreferenciaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !services.EsCanalRecordatorio(req.Canal) {
+		c.Error(services.ErrCanalInvalido)
+		return
+	}
+
+	if err := services.GuardarPreferenciaNotificacion(uint(userID), req.Canal, *req.Recordatorios); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.GetPreferenciasNotificacion(uint(userID)))
+}
+
+// UpdateIdiomaUsuario guarda el idioma en el que el usuario recibe los mensajes de la API.
+func UpdateIdiomaUsuario(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if !puedeAccederUsuario(c, uint(userID)) {
+		c.Error(apperrors.ErrPermisosInsuficientes)
+		return
+	}
+
+	var req IdiomaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := services.GuardarIdiomaUsuario(uint(userID), req.Idioma); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Si el usuario cambió su propio idioma, la respuesta ya sale en el nuevo
+	if c.GetUint("user_id") == uint(userID) {
+		c.Set("user_idioma", req.Idioma)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"idioma": req.Idioma})
+}
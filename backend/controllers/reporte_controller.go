@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rangoReporte lee desde y hasta de la query.
+func rangoReporte(c *gin.Context) (services.RangoReporte, error) {
+	return services.NuevoRangoReporte(c.Query("desde"), c.Query("hasta"), time.Now())
+}
+
+// responderReporte devuelve las filas en JSON o, con formato=csv, como
+// archivo CSV con una columna por campo.
+func responderReporte(c *gin.Context, nombre string, filas interface{}, encabezados []string, n int, fila func(i int) []string) {
+	if c.Query("formato") != "csv" {
+		c.JSON(http.StatusOK, filas)
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(encabezados)
+	for i := 0; i < n; i++ {
+		w.Write(fila(i))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="reporte-%s.csv"`, nombre))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func formatearPorcentaje(valor float64) string {
+	return strconv.FormatFloat(valor, 'f', 2, 64)
+}
+
+func GetReporteOcupacion(c *gin.Context) {
+	rango, err := rangoReporte(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filas, err := services.ReporteOcupacion(c.DefaultQuery("agrupar", "actividad"), rango)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responderReporte(c, "ocupacion", filas,
+		[]string{"grupo", "actividades", "cupo_total", "inscripciones", "ocupacion"}, len(filas),
+		func(i int) []string {
+			f := filas[i]
+			return []string{f.Grupo, strconv.Itoa(f.Actividades), strconv.Itoa(f.CupoTotal),
+				strconv.Itoa(f.Inscripciones), formatearPorcentaje(f.Ocupacion)}
+		})
+}
+
+func GetReporteTendencia(c *gin.Context) {
+	rango, err := rangoReporte(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filas, err := services.ReporteTendencia(c.DefaultQuery("periodo", "dia"), rango)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responderReporte(c, "tendencia", filas,
+		[]string{"periodo", "inscripciones", "cancelaciones"}, len(filas),
+		func(i int) []string {
+			f := filas[i]
+			return []string{f.Periodo, strconv.Itoa(f.Inscripciones), strconv.Itoa(f.Cancelaciones)}
+		})
+}
+
+func GetReporteCancelaciones(c *gin.Context) {
+	rango, err := rangoReporte(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filas, err := services.ReporteCancelaciones(rango)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responderReporte(c, "cancelaciones", filas,
+		[]string{"actividad_id", "actividad", "inscripciones", "cancelaciones", "tardias", "ausencias", "tasa_cancelacion"}, len(filas),
+		func(i int) []string {
+			f := filas[i]
+			return []string{strconv.FormatUint(uint64(f.ActividadID), 10), f.Actividad, strconv.Itoa(f.Inscripciones),
+				strconv.Itoa(f.Cancelaciones), strconv.Itoa(f.Tardias), strconv.Itoa(f.Ausencias),
+				formatearPorcentaje(f.TasaCancelacion)}
+		})
+}
+
+// GetReporteFranjas ordena los horarios por ocupación; con orden=asc muestra
+// primero los menos concurridos.
+func GetReporteFranjas(c *gin.Context) {
+	rango, err := rangoReporte(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limite, _ := strconv.Atoi(c.Query("limite"))
+	filas, err := services.ReporteFranjas(rango, c.Query("orden") == "asc", limite)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responderReporte(c, "franjas", filas,
+		[]string{"dia", "horario", "actividades", "cupo_total", "inscripciones", "ocupacion"}, len(filas),
+		func(i int) []string {
+			f := filas[i]
+			return []string{f.Dia, f.Horario, strconv.Itoa(f.Actividades), strconv.Itoa(f.CupoTotal),
+				strconv.Itoa(f.Inscripciones), formatearPorcentaje(f.Ocupacion)}
+		})
+}
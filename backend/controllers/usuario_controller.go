@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CambiarTipoRequest struct {
+	Tipo string `json:"tipo" binding:"required,oneof=socio administrador"`
+}
+
+type SuspenderUsuarioRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+// UsuarioAdmin agrega a la vista del administrador la fecha de borrado, que
+// no se expone en el resto de la API.
+type UsuarioAdmin struct {
+	models.Usuario
+	EliminadoAt      *time.Time `json:"eliminado_at"`
+	IntentosFallidos int        `json:"intentos_fallidos"`
+}
+
+func usuarioAdmin(usuario models.Usuario) UsuarioAdmin {
+	vista := UsuarioAdmin{Usuario: usuario, IntentosFallidos: usuario.IntentosFallidos}
+	if usuario.DeletedAt.Valid {
+		vista.EliminadoAt = &usuario.DeletedAt.Time
+	}
+	return vista
+}
+
+func GetUsuariosAdmin(c *gin.Context) {
+	paginacion, err := leerPaginacion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	usuarios, total, err := services.ListarUsuarios(services.FiltroUsuarios{
+		Busqueda:  c.Query("search"),
+		Tipo:      c.Query("tipo"),
+		Estado:    c.Query("estado"),
+		Pagina:    paginacion.Pagina,
+		PorPagina: paginacion.PorPagina,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	vistas := make([]UsuarioAdmin, len(usuarios))
+	for i, usuario := range usuarios {
+		vistas[i] = usuarioAdmin(usuario)
+	}
+
+	c.JSON(http.StatusOK, Pagina{
+		Datos:     vistas,
+		Total:     total,
+		Pagina:    paginacion.Pagina,
+		PorPagina: paginacion.PorPagina,
+	})
+}
+
+func GetUsuarioAdmin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	usuario, err := services.GetUsuario(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func UpdateUsuarioAdmin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req services.ActualizarUsuarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	usuario, err := services.ActualizarUsuario(uint(id), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func CambiarTipoUsuario(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req CambiarTipoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	usuario, err := services.CambiarTipoUsuario(c.GetUint("user_id"), uint(id), req.Tipo)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func SuspenderUsuario(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	var req SuspenderUsuarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	usuario, err := services.SuspenderUsuario(c.GetUint("user_id"), uint(id), req.Motivo)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func ReactivarUsuario(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	usuario, err := services.ReactivarUsuario(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func DesbloquearUsuario(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	usuario, err := services.DesbloquearUsuario(c.GetUint("user_id"), uint(id), c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+func DeleteUsuarioAdmin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if err := services.EliminarUsuario(c.GetUint("user_id"), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func RestaurarUsuario(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	usuario, err := services.RestaurarUsuario(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarioAdmin(*usuario))
+}
+
+// GetInscripcionesUsuarioAdmin lista las inscripciones de cualquier usuario,
+// aunque la cuenta esté suspendida o eliminada.
+func GetInscripcionesUsuarioAdmin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.ErrIDInvalido)
+		return
+	}
+
+	if _, err := services.GetUsuario(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var inscripciones []models.Inscripcion
+	if err := config.DB.Preload("Actividad").Where("usuario_id = ?", uint(id)).
+		Order("fecha_inscripcion DESC").Find(&inscripciones).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inscripciones)
+}
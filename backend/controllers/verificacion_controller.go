@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+
+	"proyecto-gym-backend/i18n"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerificarEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func VerificarEmail(c *gin.Context) {
+	var req VerificarEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if _, err := services.VerificarEmail(req.Token, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.Idioma(c), "EMAIL_VERIFICADO", nil)})
+}
+
+func ReenviarVerificacionEmail(c *gin.Context) {
+	if err := services.ReenviarVerificacionEmail(c.GetUint("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": i18n.T(i18n.Idioma(c), "VERIFICACION_REENVIADA", nil)})
+}
@@ -0,0 +1,11 @@
+// Package docs contiene la especificación OpenAPI de la API. Cada ruta nueva
+// debe documentarse en openapi.json; cmd/openapi-check verifica en CI que la
+// especificación y las rutas registradas en Gin coincidan.
+package docs
+
+import (
+	_ "embed"
+)
+
+//go:embed openapi.json
+var OpenAPI []byte
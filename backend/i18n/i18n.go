@@ -0,0 +1,148 @@
+// Package i18n traduce los mensajes de la API. Los textos se buscan por
+// código en los catálogos de mensajes/ y el idioma se elige según la
+// preferencia del usuario o el encabezado Accept-Language.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	estranslations "github.com/go-playground/validator/v10/translations/es"
+	"golang.org/x/text/language"
+)
+
+// Idiomas soportados
+const (
+	Espanol = "es"
+	Ingles  = "en"
+
+	IdiomaPorDefecto = Espanol
+)
+
+//go:embed mensajes/*.json
+var mensajesFS embed.FS
+
+var (
+	catalogos   = map[string]map[string]string{}
+	traductores *ut.UniversalTranslator
+	matcher     = language.NewMatcher([]language.Tag{language.Spanish, language.English})
+)
+
+func init() {
+	for _, idioma := range []string{Espanol, Ingles} {
+		contenido, err := mensajesFS.ReadFile("mensajes/" + idioma + ".json")
+		if err != nil {
+			panic(err)
+		}
+		catalogo := map[string]string{}
+		if err := json.Unmarshal(contenido, &catalogo); err != nil {
+			panic(fmt.Sprintf("catálogo %s inválido: %v", idioma, err))
+		}
+		catalogos[idioma] = catalogo
+	}
+
+	// Traducciones de los mensajes del validator que usa Gin
+	traductores = ut.New(es.New(), es.New(), en.New())
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	traductorES, _ := traductores.GetTranslator(Espanol)
+	traductorEN, _ := traductores.GetTranslator(Ingles)
+	if err := estranslations.RegisterDefaultTranslations(v, traductorES); err != nil {
+		panic(err)
+	}
+	if err := entranslations.RegisterDefaultTranslations(v, traductorEN); err != nil {
+		panic(err)
+	}
+}
+
+// Soportado indica si hay catálogo de mensajes para el idioma.
+func Soportado(idioma string) bool {
+	_, ok := catalogos[idioma]
+	return ok
+}
+
+// Negociar elige el idioma soportado que mejor coincide con un encabezado Accept-Language.
+func Negociar(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return IdiomaPorDefecto
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return IdiomaPorDefecto
+	}
+	_, indice, confianza := matcher.Match(tags...)
+	if confianza == language.No {
+		return IdiomaPorDefecto
+	}
+	return []string{Espanol, Ingles}[indice]
+}
+
+// Idioma devuelve el idioma de la petición: la preferencia guardada del
+// usuario autenticado o, si no tiene, el que pide el cliente.
+func Idioma(c *gin.Context) string {
+	if idioma := c.GetString("user_idioma"); Soportado(idioma) {
+		return idioma
+	}
+	return Negociar(c.GetHeader("Accept-Language"))
+}
+
+// T traduce un código del catálogo reemplazando los {parametros} con los
+// datos recibidos. Si el código no existe en el idioma se usa el español, y
+// si tampoco existe se devuelve el código.
+func T(idioma, codigo string, datos map[string]interface{}) string {
+	texto, ok := catalogos[idioma][codigo]
+	if !ok {
+		texto, ok = catalogos[IdiomaPorDefecto][codigo]
+	}
+	if !ok {
+		return codigo
+	}
+
+	for clave, valor := range datos {
+		texto = strings.ReplaceAll(texto, "{"+clave+"}", formatear(idioma, valor))
+	}
+	return texto
+}
+
+// Existe indica si el código tiene texto en el catálogo.
+func Existe(codigo string) bool {
+	_, ok := catalogos[IdiomaPorDefecto][codigo]
+	return ok
+}
+
+// Traductor devuelve el traductor del validator para el idioma.
+func Traductor(idioma string) ut.Translator {
+	traductor, _ := traductores.GetTranslator(idioma)
+	return traductor
+}
+
+func formatear(idioma string, valor interface{}) string {
+	formato := "02/01/2006 15:04"
+	if idioma == Ingles {
+		formato = "2006-01-02 15:04"
+	}
+
+	switch v := valor.(type) {
+	case time.Time:
+		return v.Format(formato)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(formato)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger envía los logs de GORM a slog. Las sentencias SQL sólo se
+// registran en nivel debug porque pueden contener datos personales.
+type GormLogger struct {
+	UmbralLenta time.Duration
+}
+
+func NewGormLogger() *GormLogger {
+	return &GormLogger{UmbralLenta: 200 * time.Millisecond}
+}
+
+func (g *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return g
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).InfoContext(ctx, msg, "args", args)
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).WarnContext(ctx, msg, "args", args)
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).ErrorContext(ctx, msg, "args", args)
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l := FromContext(ctx)
+	duracion := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		_, filas := fc()
+		l.ErrorContext(ctx, "error en consulta SQL", "error", err, "filas", filas, "duracion_ms", duracion.Milliseconds())
+	case duracion > g.UmbralLenta:
+		_, filas := fc()
+		l.WarnContext(ctx, "consulta SQL lenta", "filas", filas, "duracion_ms", duracion.Milliseconds())
+	case l.Enabled(ctx, slog.LevelDebug):
+		sql, filas := fc()
+		l.DebugContext(ctx, "consulta SQL", "sql", sql, "filas", filas, "duracion_ms", duracion.Milliseconds())
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type contextKey struct{}
+
+// Campos con datos personales que nunca deben llegar a los logs
+var camposSensibles = map[string]bool{
+	"email":         true,
+	"nombre":        true,
+	"password":      true,
+	"token":         true,
+	"authorization": true,
+	"destinatario":  true,
+}
+
+const valorOculto = "[REDACTADO]"
+
+// Init configura el logger global (slog) con el nivel y formato indicados.
+// El formato puede ser "json" (por defecto) o "text".
+func Init(nivel, formato string) *slog.Logger {
+	opciones := &slog.HandlerOptions{
+		Level:       parseNivel(nivel),
+		ReplaceAttr: ocultarDatosSensibles,
+	}
+
+	var handler slog.Handler
+	if strings.ToLower(formato) == "text" {
+		handler = slog.NewTextHandler(os.Stdout, opciones)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opciones)
+	}
+
+	l := slog.New(handler)
+	slog.SetDefault(l)
+	return l
+}
+
+func parseNivel(nivel string) slog.Level {
+	switch strings.ToLower(nivel) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func ocultarDatosSensibles(groups []string, a slog.Attr) slog.Attr {
+	if camposSensibles[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, valorOculto)
+	}
+	return a
+}
+
+// WithContext devuelve un contexto que lleva el logger indicado.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext devuelve el logger asociado al contexto (con el request_id de
+// la petición, por ejemplo) o el logger global si no hay ninguno.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return slog.Default()
+}
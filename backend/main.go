@@ -1,14 +1,24 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"proyecto-gym-backend/config"
 	"proyecto-gym-backend/controllers"
+	"proyecto-gym-backend/logger"
+	"proyecto-gym-backend/metrics"
 	"proyecto-gym-backend/middleware"
 	"proyecto-gym-backend/models"
+	"proyecto-gym-backend/routes"
 	"proyecto-gym-backend/services"
 
 	"github.com/gin-contrib/cors"
@@ -18,64 +28,174 @@ import (
 
 func main() {
 	// Cargar variables de entorno
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	envErr := godotenv.Load()
+
+	// Cargar y validar configuración
+	cfg, err := config.Cargar(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	config.App = cfg
+
+	// Configurar logger estructurado
+	logger.Init(cfg.Log.Nivel, cfg.Log.Formato)
+	if envErr != nil {
+		slog.Info("no se encontró archivo .env")
 	}
+	slog.Info("configuración cargada", "perfil", cfg.Perfil)
 
 	// Inicializar base de datos
 	config.InitDB()
+	if err := metrics.RegistrarGORM(config.DB); err != nil {
+		slog.Error("error registrando métricas de base de datos", "error", err)
+		os.Exit(1)
+	}
+
+	// Las cuentas creadas antes de exigir la verificación de email se dan
+	// por verificadas cuando la migración agrega la columna
+	verificacionNueva := !config.DB.Migrator().HasColumn(&models.Usuario{}, "EmailVerificadoAt")
 
 	// Auto-migrar modelos
-	config.DB.AutoMigrate(&models.Usuario{}, &models.Actividad{}, &models.Inscripcion{})
+	if err := config.Migrar(models.Todos()...); err != nil {
+		slog.Error("error ejecutando migraciones", "error", err)
+	} else if verificacionNueva {
+		if err := services.MarcarEmailsVerificados(); err != nil {
+			slog.Error("error marcando los emails existentes como verificados", "error", err)
+		}
+	}
+
+	// Contexto que se cancela al recibir SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
 
 	// Crear usuario administrador por defecto si no existe
 	services.CreateDefaultAdmin()
 
+	// Inicializar proveedor de pagos
+	if err := services.InitPagos(); err != nil {
+		slog.Error("error inicializando pagos", "error", err)
+		os.Exit(1)
+	}
+
+	// Inicializar el broker de cambios de cupo
+	if err := services.InitDisponibilidad(); err != nil {
+		slog.Error("error inicializando disponibilidad", "error", err)
+		os.Exit(1)
+	}
+
+	// Inicializar el almacén de los límites de tasa
+	if err := services.InitLimites(); err != nil {
+		slog.Error("error inicializando límites de tasa", "error", err)
+		os.Exit(1)
+	}
+
+	// Inicializar notificaciones y worker de la bandeja de salida
+	if err := services.InitNotificaciones(); err != nil {
+		slog.Error("error inicializando notificaciones", "error", err)
+		os.Exit(1)
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		services.IniciarWorkerNotificaciones(ctx)
+	}()
+
+	// Scheduler de recordatorios de clases
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		services.IniciarSchedulerRecordatorios(ctx)
+	}()
+
+	// Limpieza de las respuestas idempotentes vencidas
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		services.IniciarLimpiezaIdempotencia(ctx)
+	}()
+
+	// Reintento de los reembolsos que la pasarela no recibió
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		services.IniciarReintentoReembolsos(ctx)
+	}()
+
+	// Limpieza de las cubetas de límites ya recargadas
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		services.IniciarLimpiezaLimites(ctx)
+	}()
+
 	// Configurar Gin
-	r := gin.Default()
+	r := gin.New()
+	// ClientIP sólo confía en X-Forwarded-For si lo agrega un proxy conocido
+	if err := r.SetTrustedProxies(cfg.Servidor.ProxiesConfiables); err != nil {
+		slog.Error("proxies confiables inválidos", "error", err)
+		os.Exit(1)
+	}
+	r.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.Metrics(), middleware.Errores(), middleware.Recuperar())
 
 	// Configurar CORS
 	r.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"http://localhost:3000", "http://localhost", "http://localhost:80"},
+		AllowOrigins:     cfg.Servidor.CORSOrigenes,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Request-ID", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Language", "X-Request-ID", "Deprecation", "Sunset", "Link", "ETag", "Idempotent-Replayed", "Retry-After"},
 		AllowCredentials: true,
 	}))
 
-	// Rutas públicas
-	public := r.Group("/api")
-	{
-		// Autenticación
-		public.POST("/login", controllers.Login)
-		public.POST("/register", controllers.Register)
-
-		// Actividades (público)
-		public.GET("/actividades", controllers.GetActividades)
-		public.GET("/actividades/:id", controllers.GetActividadByID)
-
-		// Inscripciones (público)
-		public.POST("/inscripciones", controllers.CreateInscripcion)
-		public.GET("/usuarios/:id/inscripciones", controllers.GetInscripcionesUsuario)
-		public.DELETE("/inscripciones/:id", controllers.DeleteInscripcion)
-	}
+	// Rutas de la API
+	routes.Registrar(r)
 
-	// Rutas protegidas (solo administradores)
-	admin := r.Group("/api/admin")
-	admin.Use(middleware.AuthMiddleware("administrador"))
-	{
-		admin.GET("/actividades", controllers.GetActividadesAdmin) // ← NUEVA RUTA CON FILTROS
-		admin.POST("/actividades", controllers.CreateActividad)
-		admin.PUT("/actividades/:id", controllers.UpdateActividad)
-		admin.DELETE("/actividades/:id", controllers.DeleteActividad)
+	port := cfg.Servidor.Puerto
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: time.Duration(cfg.Servidor.ReadHeaderTimeoutSegundos) * time.Second,
+		ReadTimeout:       time.Duration(cfg.Servidor.ReadTimeoutSegundos) * time.Second,
+		WriteTimeout:      time.Duration(cfg.Servidor.WriteTimeoutSegundos) * time.Second,
+		IdleTimeout:       time.Duration(cfg.Servidor.IdleTimeoutSegundos) * time.Second,
 	}
+	// Los streams SSE no terminan solos: se cortan al empezar el apagado
+	srv.RegisterOnShutdown(services.CerrarDisponibilidad)
+
+	go func() {
+		slog.Info("gimnasio backend iniciado", "puerto", port, "api", "http://localhost:"+port+"/api/v1")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("error en el servidor HTTP", "error", err)
+			os.Exit(1)
+		}
+	}()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	<-ctx.Done()
+	stop()
+	slog.Info("apagando servidor, esperando peticiones en curso")
+	controllers.MarcarApagado()
+
+	// Drenar peticiones en curso y esperar a que terminen los workers
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(cfg.Servidor.ShutdownTimeoutSegundos)*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error apagando el servidor HTTP", "error", err)
+	}
+	terminados := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(terminados)
+	}()
+	select {
+	case <-terminados:
+	case <-shutdownCtx.Done():
+		slog.Warn("los workers no terminaron dentro del plazo de apagado")
 	}
 
-	log.Printf("🏋️‍♂️ Gimnasio Backend iniciado en puerto %s", port)
-	log.Printf("🌐 API disponible en: http://localhost:%s/api", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	if sqlDB, err := config.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+	slog.Info("servidor detenido")
 }
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const claveInicio = "metrics:inicio"
+
+// RegistrarGORM agrega callbacks a GORM para medir la duración de cada consulta.
+func RegistrarGORM(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("metrics:antes_create", iniciar); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("metrics:despues_create", observar("create")); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("metrics:antes_query", iniciar); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("metrics:despues_query", observar("query")); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("metrics:antes_update", iniciar); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("metrics:despues_update", observar("update")); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("metrics:antes_delete", iniciar); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("metrics:despues_delete", observar("delete")); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("metrics:antes_row", iniciar); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("metrics:despues_row", observar("row")); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("metrics:antes_raw", iniciar); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("metrics:despues_raw", observar("raw"))
+}
+
+func iniciar(db *gorm.DB) {
+	db.InstanceSet(claveInicio, time.Now())
+}
+
+func observar(operacion string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		valor, ok := db.InstanceGet(claveInicio)
+		if !ok {
+			return
+		}
+		inicio, ok := valor.(time.Time)
+		if !ok {
+			return
+		}
+
+		tabla := db.Statement.Table
+		if tabla == "" {
+			tabla = "desconocida"
+		}
+		DBDuracion.WithLabelValues(operacion, tabla).Observe(time.Since(inicio).Seconds())
+	}
+}
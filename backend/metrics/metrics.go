@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm/clause"
+)
+
+// Métricas HTTP
+var (
+	HTTPDuracion = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gym_http_request_duration_seconds",
+		Help:    "Duración de las peticiones HTTP por ruta de Gin.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"metodo", "ruta", "status"})
+
+	HTTPErrores = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gym_http_errors_total",
+		Help: "Respuestas HTTP con status 4xx o 5xx por ruta de Gin.",
+	}, []string{"metodo", "ruta", "status"})
+)
+
+// Métricas de base de datos
+var DBDuracion = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "gym_db_query_duration_seconds",
+	Help:    "Duración de las consultas a la base de datos por operación y tabla.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"operacion", "tabla"})
+
+// Métricas de negocio
+var (
+	InscripcionesCreadas = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gym_inscripciones_creadas_total",
+		Help: "Inscripciones creadas.",
+	})
+
+	InscripcionesCanceladas = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gym_inscripciones_canceladas_total",
+		Help: "Inscripciones dadas de baja, por tipo de cancelación.",
+	}, []string{"tipo"})
+
+	LoginsFallidos = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gym_logins_fallidos_total",
+		Help: "Intentos de login con credenciales inválidas.",
+	})
+
+	LimitesExcedidos = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gym_limites_excedidos_total",
+		Help: "Peticiones rechazadas por límite de tasa, por ámbito.",
+	}, []string{"ambito"})
+
+	StreamsDisponibilidad = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "gym_streams_disponibilidad",
+		Help: "Clientes conectados al stream de cambios de cupo.",
+	})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "gym_actividades_completas",
+		Help: "Actividades sin cupo disponible.",
+	}, actividadesCompletas)
+)
+
+// Tipos de cancelación para InscripcionesCanceladas
+const (
+	CancelacionSocio  = "socio"
+	CancelacionTardia = "tardia"
+	CancelacionAdmin  = "admin"
+)
+
+// actividadesCompletas cuenta las actividades sin cupo. Las inscripciones se
+// unen por la clave de la tabla de actividades para no depender de los
+// nombres de tabla que genera GORM.
+func actividadesCompletas() float64 {
+	if config.DB == nil {
+		return 0
+	}
+
+	inscripciones := config.DB.Model(&models.Inscripcion{}).
+		Select("actividad_id, COUNT(*) AS total").
+		Group("actividad_id")
+
+	var completas int64
+	config.DB.Model(&models.Actividad{}).
+		Joins("LEFT JOIN (?) i ON i.actividad_id = ?", inscripciones,
+			clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}).
+		Where("cupo_maximo <= COALESCE(i.total, 0)").
+		Count(&completas)
+	return float64(completas)
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestActividadesCompletas(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cada conexión a :memory: es una base distinta
+	sqlDB.SetMaxOpenConns(1)
+	anterior := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = anterior
+		sqlDB.Close()
+	})
+	if err := config.Migrar(models.Todos()...); err != nil {
+		t.Fatal(err)
+	}
+
+	actividad := func(cupo, inscriptos, bajas int, eliminada bool) {
+		a := &models.Actividad{Titulo: "Yoga", Categoria: "General", Dia: "Lunes", Horario: "18:00",
+			DuracionMinutos: 60, CupoMaximo: cupo, Profesor: "Profe"}
+		if err := db.Create(a).Error; err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < inscriptos+bajas; i++ {
+			inscripcion := &models.Inscripcion{UsuarioID: uint(i + 1), ActividadID: a.ID}
+			if err := db.Create(inscripcion).Error; err != nil {
+				t.Fatal(err)
+			}
+			if i >= inscriptos {
+				db.Delete(inscripcion)
+			}
+		}
+		if eliminada {
+			db.Delete(a)
+		}
+	}
+	actividad(1, 1, 0, false) // completa
+	actividad(2, 3, 0, false) // con sobrecupo
+	actividad(2, 1, 1, false) // la baja libera el lugar
+	actividad(1, 0, 0, false) // sin inscriptos
+	actividad(1, 1, 0, true)  // eliminada
+
+	if got := actividadesCompletas(); got != 2 {
+		t.Fatalf("actividades completas = %v, se esperaban 2", got)
+	}
+}
@@ -1,9 +1,10 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
+	"proyecto-gym-backend/apperrors"
 	"proyecto-gym-backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -11,30 +12,72 @@ import (
 
 func AuthMiddleware(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autorización requerido"})
+		if c.GetHeader("Authorization") == "" {
+			c.Error(apperrors.ErrTokenRequerido)
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := services.ValidateJWT(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
-			c.Abort()
+		if !autenticar(c) {
 			return
 		}
 
-		if requiredRole != "" && claims.Tipo != requiredRole {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Permisos insuficientes"})
+		if requiredRole != "" && c.GetString("user_tipo") != requiredRole {
+			c.Error(apperrors.ErrPermisosInsuficientes)
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_tipo", claims.Tipo)
+// AuthOpcional identifica al usuario si la petición trae token, para rutas
+// públicas que personalizan la respuesta. Un token inválido se rechaza igual.
+func AuthOpcional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !autenticar(c) {
+			return
+		}
 		c.Next()
 	}
 }
+
+// autenticar valida el token y guarda los datos del usuario en el contexto.
+// Si falla registra el error, aborta la petición y devuelve false.
+func autenticar(c *gin.Context) bool {
+	tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	claims, err := services.ValidateJWT(tokenString)
+	if err != nil {
+		c.Error(apperrors.ErrTokenInvalido.ConCausa(err))
+		c.Abort()
+		return false
+	}
+
+	// El rol y el estado se leen de la base para que los cambios de un
+	// administrador apliquen sin esperar a que venza el token
+	usuario, err := services.GetUsuarioSesion(claims.UserID)
+	if err != nil {
+		c.Error(apperrors.ErrTokenInvalido.ConCausa(err))
+		c.Abort()
+		return false
+	}
+
+	// Cambiar o restablecer la contraseña cierra las sesiones anteriores
+	if claims.Sesion != usuario.VersionSesion {
+		c.Error(apperrors.ErrTokenInvalido.ConCausa(errors.New("sesión revocada")))
+		c.Abort()
+		return false
+	}
+
+	if usuario.SuspendidoAt != nil {
+		c.Error(services.ErrCuentaSuspendida)
+		c.Abort()
+		return false
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_tipo", usuario.Tipo)
+	c.Set("user_idioma", usuario.Idioma)
+	return true
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecacion describe el aviso que se envía en las rutas que van a dejar de existir.
+type Deprecacion struct {
+	Desde  time.Time // fecha desde la que la ruta está deprecada
+	Sunset time.Time // fecha en que se retira; cero si todavía no se decidió
+	Enlace string    // documentación del cambio o de la versión que la reemplaza
+}
+
+// Deprecado agrega los encabezados Deprecation (RFC 9745), Sunset (RFC 8594)
+// y Link a las respuestas de las rutas del grupo.
+func Deprecado(aviso Deprecacion) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", aviso.Desde.Unix())
+	sunset := ""
+	if !aviso.Sunset.IsZero() {
+		sunset = aviso.Sunset.UTC().Format(http.TimeFormat)
+	}
+	link := ""
+	if aviso.Enlace != "" {
+		link = fmt.Sprintf(`<%s>; rel="deprecation"`, aviso.Enlace)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if link != "" {
+			c.Header("Link", link)
+		}
+		c.Next()
+	}
+}
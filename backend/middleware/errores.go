@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/i18n"
+	"proyecto-gym-backend/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errores renderiza el último error agregado con c.Error usando el sobre
+// común de la API. Los errores internos se registran completos en el log y
+// al cliente sólo le llega el mensaje genérico. El mensaje se traduce al
+// idioma de la petición.
+func Errores() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		idioma := i18n.Idioma(c)
+		appErr := apperrors.Desde(c.Errors.Last().Err).Localizar(idioma)
+		if appErr.Status >= 500 {
+			ctx := c.Request.Context()
+			logger.FromContext(ctx).ErrorContext(ctx, "error interno", "error", c.Errors.Last().Err)
+		}
+
+		respuesta := gin.H{
+			"error":  appErr.Mensaje,
+			"codigo": appErr.Codigo,
+		}
+		if appErr.Campos != nil {
+			respuesta["campos"] = appErr.Campos
+		}
+		if len(appErr.Detalles) > 0 {
+			respuesta["detalles"] = appErr.Detalles
+		}
+		if requestID := c.GetString("request_id"); requestID != "" {
+			respuesta["request_id"] = requestID
+		}
+
+		c.Header("Content-Language", idioma)
+		c.JSON(appErr.Status, respuesta)
+	}
+}
+
+// Recuperar convierte un panic en un error interno que renderiza Errores.
+func Recuperar() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recuperado interface{}) {
+		_ = c.Error(fmt.Errorf("panic: %v", recuperado))
+		c.Abort()
+	})
+}
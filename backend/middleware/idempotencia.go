@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/logger"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotencia permite reintentar un POST sin repetir su efecto. Si la
+// petición trae Idempotency-Key, la primera respuesta exitosa se guarda por
+// usuario, ruta y clave, y los reintentos la reciben tal cual con el header
+// Idempotent-Replayed. Las respuestas de error no se guardan: liberan la
+// clave para que el cliente pueda volver a intentar. Debe ir después de la
+// autenticación para que la clave quede asociada al usuario: sin usuario la
+// petición con clave se rechaza, para no compartir claves entre clientes.
+func Idempotencia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clave := c.GetHeader("Idempotency-Key")
+		if clave == "" {
+			c.Next()
+			return
+		}
+		usuarioID := c.GetUint("user_id")
+		if usuarioID == 0 {
+			c.Error(apperrors.ErrTokenRequerido)
+			c.Abort()
+			return
+		}
+		if len(clave) > services.LargoMaximoClaveIdempotencia {
+			c.Error(services.ErrClaveIdempotenciaInvalida)
+			c.Abort()
+			return
+		}
+
+		cuerpo, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(apperrors.ErrDatosInvalidos.ConCausa(err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(cuerpo))
+		suma := sha256.Sum256(cuerpo)
+
+		// La misma operación por /api/v1 o por el alias /api comparte la clave
+		ruta := c.Request.Method + " " + strings.TrimPrefix(strings.TrimPrefix(c.FullPath(), "/api"), "/v1")
+
+		registro, nueva, err := services.ReservarClaveIdempotencia(usuarioID, ruta, clave, hex.EncodeToString(suma[:]))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !nueva {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(registro.Status, registro.TipoContenido, registro.Cuerpo)
+			c.Abort()
+			return
+		}
+
+		grabador := &grabadorRespuesta{ResponseWriter: c.Writer}
+		c.Writer = grabador
+		c.Next()
+
+		log := logger.FromContext(c.Request.Context())
+		status := grabador.Status()
+		if !grabador.Written() || status >= 400 {
+			if err := services.LiberarClaveIdempotencia(registro.ID); err != nil {
+				log.Error("no se pudo liberar la clave de idempotencia", "error", err)
+			}
+			return
+		}
+		if err := services.GuardarRespuestaIdempotencia(registro.ID, status, grabador.Header().Get("Content-Type"), grabador.cuerpo.Bytes()); err != nil {
+			log.Error("no se pudo guardar la respuesta idempotente", "error", err)
+		}
+	}
+}
+
+// grabadorRespuesta copia el cuerpo de la respuesta mientras se envía.
+type grabadorRespuesta struct {
+	gin.ResponseWriter
+	cuerpo bytes.Buffer
+}
+
+func (g *grabadorRespuesta) Write(datos []byte) (int, error) {
+	g.cuerpo.Write(datos)
+	return g.ResponseWriter.Write(datos)
+}
+
+func (g *grabadorRespuesta) WriteString(s string) (int, error) {
+	g.cuerpo.WriteString(s)
+	return g.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"proyecto-gym-backend/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotenciaSinUsuario(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	casos := []struct {
+		nombre    string
+		clave     string
+		ejecutado bool
+		err       error
+	}{
+		{"sin clave se procesa normalmente", "", true, nil},
+		{"con clave se rechaza", "3f1c9a", false, apperrors.ErrTokenRequerido},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			var errs []*gin.Error
+			ejecutado := false
+			r := gin.New()
+			r.POST("/inscripciones", func(c *gin.Context) {
+				c.Next()
+				errs = c.Errors
+			}, Idempotencia(), func(c *gin.Context) {
+				ejecutado = true
+				c.Status(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/inscripciones", strings.NewReader(`{"actividad_id":1}`))
+			if caso.clave != "" {
+				req.Header.Set("Idempotency-Key", caso.clave)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if ejecutado != caso.ejecutado {
+				t.Errorf("handler ejecutado = %v, se esperaba %v", ejecutado, caso.ejecutado)
+			}
+			if caso.err == nil {
+				if len(errs) > 0 {
+					t.Errorf("errores inesperados: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !errors.Is(errs[0].Err, caso.err) {
+				t.Errorf("errores = %v, se esperaba %v", errs, caso.err)
+			}
+		})
+	}
+}
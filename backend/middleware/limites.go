@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"proyecto-gym-backend/logger"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitarTasa rechaza con 429 las peticiones que agotan la cubeta de fichas
+// del ámbito para la clave que devuelve clave. Una clave vacía no se limita.
+func LimitarTasa(ambito string, clave func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		valor := clave(c)
+		if valor == "" {
+			c.Next()
+			return
+		}
+
+		permitido, espera := services.ConsumirLimite(ambito, valor)
+		if !permitido {
+			segundos := int(math.Ceil(espera.Seconds()))
+			metrics.LimitesExcedidos.WithLabelValues(ambito).Inc()
+			logger.FromContext(c.Request.Context()).Warn("límite de tasa excedido", "ambito", ambito, "ip", c.ClientIP())
+
+			c.Header("Retry-After", strconv.Itoa(segundos))
+			c.Error(services.ErrDemasiadosIntentos.ConDetalle("reintentar_en", segundos))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// PorIP usa la IP del cliente como clave del límite.
+func PorIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// PorUsuario usa el usuario autenticado como clave; va después de AuthMiddleware.
+func PorUsuario(c *gin.Context) string {
+	if id := c.GetUint("user_id"); id != 0 {
+		return strconv.FormatUint(uint64(id), 10)
+	}
+	return ""
+}
+
+// maxCuerpoEmail es el tamaño máximo que PorEmail lee del cuerpo: las rutas
+// que lo usan son públicas y sus cuerpos legítimos son mucho menores.
+const maxCuerpoEmail = 8 << 10
+
+// PorEmail usa como clave el email del cuerpo JSON, para limitar los
+// intentos contra una misma cuenta aunque lleguen desde distintas IP. Un
+// cuerpo demasiado grande llega cortado al handler, que lo rechaza.
+func PorEmail(c *gin.Context) string {
+	cuerpo, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCuerpoEmail))
+	c.Request.Body = io.NopCloser(bytes.NewReader(cuerpo))
+	if err != nil {
+		return ""
+	}
+
+	var datos struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(cuerpo, &datos) != nil {
+		return ""
+	}
+	return services.ClaveCuenta(datos.Email)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPorEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	casos := []struct {
+		nombre  string
+		cuerpo  string
+		clave   string
+		bindeOK bool // el handler todavía puede leer el cuerpo
+	}{
+		{"email normalizado", `{"email":" Socio@Gym.Test ","password":"x"}`, "socio@gym.test", true},
+		{"sin email", `{"password":"x"}`, "", true},
+		{"json inválido", `{`, "", false},
+		{"cuerpo demasiado grande", `{"email":"socio@gym.test","relleno":"` + strings.Repeat("a", maxCuerpoEmail) + `"}`, "", false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			var clave string
+			bindeOK := false
+			r := gin.New()
+			r.POST("/login", func(c *gin.Context) {
+				clave = PorEmail(c)
+				c.Next()
+			}, func(c *gin.Context) {
+				var datos map[string]interface{}
+				bindeOK = json.NewDecoder(c.Request.Body).Decode(&datos) == nil
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(caso.cuerpo))
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if clave != caso.clave {
+				t.Errorf("clave = %q, se esperaba %q", clave, caso.clave)
+			}
+			if bindeOK != caso.bindeOK {
+				t.Errorf("el handler leyó el cuerpo = %v, se esperaba %v", bindeOK, caso.bindeOK)
+			}
+		})
+	}
+}
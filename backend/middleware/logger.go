@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger registra una línea estructurada por petición. Reemplaza al
+// logger por defecto de Gin.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		nivel := slog.LevelInfo
+		switch {
+		case status >= 500:
+			nivel = slog.LevelError
+		case status >= 400:
+			nivel = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("metodo", c.Request.Method),
+			slog.String("ruta", c.FullPath()),
+			slog.String("path", pathSinSecretos(c)),
+			slog.Int("status", status),
+			slog.Int64("duracion_ms", time.Since(inicio).Milliseconds()),
+			slog.String("ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, slog.Any("usuario_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errores", c.Errors.String()))
+		}
+
+		ctx := c.Request.Context()
+		logger.FromContext(ctx).LogAttrs(ctx, nivel, "petición HTTP", attrs...)
+	}
+}
+
+// parametrosSecretos son los parámetros de ruta que funcionan como credencial
+// (por ejemplo, el token del feed iCal) y no deben quedar en los logs.
+var parametrosSecretos = map[string]bool{
+	"token": true,
+}
+
+const valorRedactado = "[redactado]"
+
+// pathSinSecretos devuelve el path de la petición con los parámetros secretos
+// reemplazados.
+func pathSinSecretos(c *gin.Context) string {
+	path := c.Request.URL.Path
+	for _, param := range c.Params {
+		if parametrosSecretos[param.Key] && param.Value != "" {
+			path = strings.ReplaceAll(path, param.Value, valorRedactado)
+		}
+	}
+	return path
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggerRedactaSecretos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var salida bytes.Buffer
+	anterior := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&salida, nil)))
+	t.Cleanup(func() { slog.SetDefault(anterior) })
+
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/calendario/socios/:token", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/actividades/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	casos := []struct {
+		url  string
+		ruta string
+		path string
+	}{
+		{"/calendario/socios/9f2c4e7a1b3d", "/calendario/socios/:token", "/calendario/socios/" + valorRedactado},
+		{"/actividades/42", "/actividades/:id", "/actividades/42"},
+		{"/no-existe", "", "/no-existe"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.url, func(t *testing.T) {
+			salida.Reset()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, caso.url, nil))
+
+			var linea struct {
+				Ruta string `json:"ruta"`
+				Path string `json:"path"`
+			}
+			if err := json.Unmarshal(salida.Bytes(), &linea); err != nil {
+				t.Fatalf("línea de log inválida %q: %v", salida.String(), err)
+			}
+			if linea.Ruta != caso.ruta || linea.Path != caso.path {
+				t.Errorf("ruta = %q, path = %q; se esperaba %q, %q", linea.Ruta, linea.Path, caso.ruta, caso.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"proyecto-gym-backend/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics mide la latencia y los errores de cada petición por ruta de Gin.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+		c.Next()
+
+		ruta := c.FullPath()
+		if ruta == "" {
+			// Sin ruta registrada (404): no se usa el path para no crear series sin límite
+			ruta = "sin_ruta"
+		}
+		status := c.Writer.Status()
+		statusStr := strconv.Itoa(status)
+
+		metrics.HTTPDuracion.WithLabelValues(c.Request.Method, ruta, statusStr).Observe(time.Since(inicio).Seconds())
+		if status >= 400 {
+			metrics.HTTPErrores.WithLabelValues(c.Request.Method, ruta, statusStr).Inc()
+		}
+	}
+}
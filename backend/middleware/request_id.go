@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"proyecto-gym-backend/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDValido = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID asigna un identificador a cada petición (o respeta el que envía
+// el cliente si es válido), lo devuelve en la respuesta y lo agrega a todas
+// las líneas de log de la petición.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDValido.MatchString(requestID) {
+			requestID = nuevoRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		l := logger.FromContext(c.Request.Context()).With("request_id", requestID)
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), l))
+
+		c.Next()
+	}
+}
+
+func nuevoRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
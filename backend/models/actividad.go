@@ -7,6 +7,7 @@ import (
 
 type Actividad struct {
 	ID              uint           `json:"id" gorm:"primaryKey"`
+	ClaveExterna    *string        `json:"clave_externa,omitempty" gorm:"type:varchar(64);uniqueIndex"` // para importar desde otros sistemas
 	Titulo          string         `json:"titulo" gorm:"not null"`
 	Categoria       string         `json:"categoria" gorm:"not null"`
 	Descripcion     string         `json:"descripcion"`
@@ -15,6 +16,7 @@ type Actividad struct {
 	DuracionMinutos int            `json:"duracion_minutos" gorm:"not null"`
 	CupoMaximo      int            `json:"cupo_maximo" gorm:"not null"`
 	Profesor        string         `json:"profesor" gorm:"not null"`
+	Sala            string         `json:"sala"`
 	FotoURL         string         `json:"foto_url"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de evento de auditoría
+const (
+	AuditoriaLoginFallido       = "login_fallido"
+	AuditoriaCuentaBloqueada    = "cuenta_bloqueada"
+	AuditoriaCuentaDesbloqueada = "cuenta_desbloqueada"
+	AuditoriaRecuperacion       = "recuperacion_solicitada"
+	AuditoriaPasswordReseteada  = "password_restablecida"
+	AuditoriaPasswordCambiada   = "password_cambiada"
+	AuditoriaEmailVerificado    = "email_verificado"
+)
+
+// EventoAuditoria registra un hecho relevante para la seguridad de una cuenta.
+type EventoAuditoria struct {
+	ID        uint                   `json:"id" gorm:"primaryKey"`
+	Tipo      string                 `json:"tipo" gorm:"type:varchar(40);not null;index"`
+	UsuarioID *uint                  `json:"usuario_id,omitempty" gorm:"index"` // cuenta afectada, si existe
+	ActorID   *uint                  `json:"actor_id,omitempty"`                // administrador que hizo la acción
+	IP        string                 `json:"ip" gorm:"type:varchar(45)"`
+	Detalles  map[string]interface{} `json:"detalles,omitempty" gorm:"type:text;serializer:json"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// BloqueoLogin cuenta los intentos fallidos de login con un email que no
+// pertenece a ninguna cuenta, para bloquearlo igual que a una cuenta real.
+type BloqueoLogin struct {
+	Email            string     `gorm:"type:varchar(191);primaryKey"`
+	IntentosFallidos int        `gorm:"not null"`
+	BloqueadoHasta   *time.Time `gorm:"type:datetime"`
+	UpdatedAt        time.Time  `gorm:"index"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// CubetaLimite es el estado de una cubeta de fichas del limitador cuando se
+// guarda en la base, para compartirlo entre varias instancias.
+type CubetaLimite struct {
+	Clave         string    `gorm:"type:varchar(191);primaryKey"`
+	Fichas        float64   `gorm:"not null"`
+	ActualizadaAt time.Time `gorm:"precision:3;not null;index"`
+}
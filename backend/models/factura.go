@@ -0,0 +1,34 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Estados posibles de una factura
+const (
+	FacturaEmitida = "emitida"
+	FacturaAnulada = "anulada"
+)
+
+type Factura struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Numero          string         `json:"numero" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Secuencia       uint           `json:"-" gorm:"uniqueIndex;not null"`
+	PagoID          uint           `json:"pago_id" gorm:"uniqueIndex;not null"`
+	UsuarioID       uint           `json:"usuario_id" gorm:"not null;index"`
+	Descripcion     string         `json:"descripcion" gorm:"not null"`
+	Monto           int64          `json:"monto" gorm:"not null"` // en centavos
+	Moneda          string         `json:"moneda" gorm:"not null;default:'ARS'"`
+	Estado          string         `json:"estado" gorm:"type:varchar(20);not null;default:'emitida'"`
+	FechaEmision    time.Time      `json:"fecha_emision" gorm:"type:datetime;not null"`
+	AnuladaAt       *time.Time     `json:"anulada_at" gorm:"type:datetime"`
+	MotivoAnulacion string         `json:"motivo_anulacion,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relaciones
+	Usuario *Usuario `json:"usuario,omitempty" gorm:"foreignKey:UsuarioID"`
+	Pago    *Pago    `json:"pago,omitempty" gorm:"foreignKey:PagoID"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// ClaveIdempotencia guarda la respuesta de un POST enviado con el header
+// Idempotency-Key, para devolver la misma respuesta si el cliente reintenta.
+// Status en cero indica que la petición original todavía se está procesando.
+type ClaveIdempotencia struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"not null;uniqueIndex:idx_idempotencia,priority:1"`
+	Ruta          string    `json:"ruta" gorm:"type:varchar(150);not null;uniqueIndex:idx_idempotencia,priority:2"`
+	Clave         string    `json:"clave" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotencia,priority:3"`
+	HashPeticion  string    `json:"-" gorm:"type:char(64);not null"`
+	Status        int       `json:"status"`
+	TipoContenido string    `json:"-" gorm:"type:varchar(100)"`
+	Cuerpo        []byte    `json:"-" gorm:"type:mediumblob"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiraAt      time.Time `json:"expira_at" gorm:"not null;index"`
+}
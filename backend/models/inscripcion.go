@@ -8,8 +8,10 @@ import (
 type Inscripcion struct {
 	ID               uint           `json:"id" gorm:"primaryKey"`
 	UsuarioID        uint           `json:"usuario_id" gorm:"not null"`
-	ActividadID      uint           `json:"actividad_id" gorm:"not null"`
-	FechaInscripcion *time.Time     `json:"fecha_inscripcion" gorm:"type:datetime"`
+	ActividadID      uint           `json:"actividad_id" gorm:"not null;index"`
+	FechaInscripcion *time.Time     `json:"fecha_inscripcion" gorm:"type:datetime;index"`
+	InscritoPor      *uint          `json:"inscrito_por,omitempty"`     // administrador que la cargó
+	MotivoSobrecupo  string         `json:"motivo_sobrecupo,omitempty"` // si se superó el cupo máximo
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
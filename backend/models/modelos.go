@@ -0,0 +1,12 @@
+package models
+
+// Todos devuelve los modelos que se migran al iniciar, en orden de creación.
+func Todos() []interface{} {
+	return []interface{}{
+		&Usuario{}, &Actividad{}, &Inscripcion{},
+		&Suscripcion{}, &Pago{}, &Factura{}, &Penalizacion{},
+		&Notificacion{}, &Recordatorio{}, &PreferenciaNotificacion{},
+		&ClaveIdempotencia{}, &EventoAuditoria{}, &CubetaLimite{},
+		&TokenUsuario{}, &BloqueoLogin{},
+	}
+}
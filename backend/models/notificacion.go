@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de una notificación en la bandeja de salida
+const (
+	NotificacionPendiente = "pendiente"
+	NotificacionEnviada   = "enviada"
+	NotificacionFallida   = "fallida"
+)
+
+// Notificacion es un mensaje de la bandeja de salida (outbox). Se escribe en
+// la misma transacción que el cambio que la origina y la entrega un worker.
+type Notificacion struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UsuarioID      *uint      `json:"usuario_id" gorm:"index"`
+	Canal          string     `json:"canal" gorm:"type:varchar(20);not null;default:'email'"`
+	Plantilla      string     `json:"plantilla" gorm:"type:varchar(50);not null"`
+	Destinatario   string     `json:"destinatario" gorm:"not null"`
+	Asunto         string     `json:"asunto" gorm:"not null"`
+	CuerpoTexto    string     `json:"-" gorm:"type:text"`
+	CuerpoHTML     string     `json:"-" gorm:"type:text"`
+	Estado         string     `json:"estado" gorm:"type:varchar(20);not null;default:'pendiente';index:idx_notificacion_cola,priority:1"`
+	Intentos       int        `json:"intentos" gorm:"not null;default:0"`
+	ProximoIntento time.Time  `json:"proximo_intento" gorm:"type:datetime;not null;index:idx_notificacion_cola,priority:2"`
+	UltimoError    string     `json:"ultimo_error,omitempty" gorm:"type:text"`
+	EnviadaAt      *time.Time `json:"enviada_at" gorm:"type:datetime"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
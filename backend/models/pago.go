@@ -0,0 +1,49 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Estados posibles de un pago
+const (
+	PagoPendiente   = "pendiente"
+	PagoAprobado    = "aprobado"
+	PagoRechazado   = "rechazado"
+	PagoCancelado   = "cancelado"
+	PagoReembolsado = "reembolsado"
+	PagoAReembolsar = "a_reembolsar" // aprobado sin poder activarse, a la espera de la devolución
+)
+
+// Conceptos de cobro
+const (
+	ConceptoSuscripcion = "suscripcion"
+	ConceptoInscripcion = "inscripcion"
+)
+
+type Pago struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UsuarioID         uint           `json:"usuario_id" gorm:"not null;index"`
+	Concepto          string         `json:"concepto" gorm:"not null"` // suscripcion, inscripcion
+	Plan              string         `json:"plan,omitempty"`
+	ActividadID       *uint          `json:"actividad_id,omitempty"`
+	SuscripcionID     *uint          `json:"suscripcion_id,omitempty"`
+	InscripcionID     *uint          `json:"inscripcion_id,omitempty"`
+	Monto             int64          `json:"monto" gorm:"not null"` // en centavos
+	Moneda            string         `json:"moneda" gorm:"not null;default:'ARS'"`
+	Estado            string         `json:"estado" gorm:"type:varchar(20);not null;default:'pendiente';index"`
+	Proveedor         string         `json:"proveedor" gorm:"type:varchar(50);not null"`
+	ReferenciaExterna string         `json:"referencia_externa" gorm:"type:varchar(100);index"`
+	URLPago           string         `json:"url_pago,omitempty"`
+	Detalle           string         `json:"detalle,omitempty"`
+	ConfirmadoAt      *time.Time     `json:"confirmado_at" gorm:"type:datetime"`
+	ReembolsoPedidoAt *time.Time     `json:"reembolso_pedido_at,omitempty" gorm:"type:datetime"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relaciones
+	Usuario     *Usuario     `json:"usuario,omitempty" gorm:"foreignKey:UsuarioID"`
+	Actividad   *Actividad   `json:"actividad,omitempty" gorm:"foreignKey:ActividadID"`
+	Suscripcion *Suscripcion `json:"suscripcion,omitempty" gorm:"foreignKey:SuscripcionID"`
+}
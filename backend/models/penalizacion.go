@@ -0,0 +1,38 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Tipos de penalización
+const (
+	PenalizacionCancelacionTardia = "cancelacion_tardia"
+	PenalizacionAusencia          = "ausencia"
+)
+
+// Estados de una penalización
+const (
+	PenalizacionActiva  = "activa"
+	PenalizacionAnulada = "anulada"
+)
+
+type Penalizacion struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UsuarioID       uint           `json:"usuario_id" gorm:"not null;index"`
+	ActividadID     uint           `json:"actividad_id" gorm:"not null"`
+	InscripcionID   uint           `json:"inscripcion_id" gorm:"not null"`
+	Tipo            string         `json:"tipo" gorm:"type:varchar(30);not null"` // cancelacion_tardia, ausencia
+	Sesion          *time.Time     `json:"sesion" gorm:"type:datetime"`
+	Estado          string         `json:"estado" gorm:"type:varchar(20);not null;default:'activa'"`
+	SuspensionHasta *time.Time     `json:"suspension_hasta" gorm:"type:datetime"`
+	AnuladaPor      *uint          `json:"anulada_por,omitempty"`
+	MotivoAnulacion string         `json:"motivo_anulacion,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relaciones
+	Usuario   *Usuario   `json:"usuario,omitempty" gorm:"foreignKey:UsuarioID"`
+	Actividad *Actividad `json:"actividad,omitempty" gorm:"foreignKey:ActividadID"`
+}
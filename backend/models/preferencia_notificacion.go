@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// PreferenciaNotificacion guarda, por usuario y canal, si quiere recibir recordatorios.
+type PreferenciaNotificacion struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"not null;uniqueIndex:idx_preferencia_canal,priority:1"`
+	Canal         string    `json:"canal" gorm:"type:varchar(20);not null;uniqueIndex:idx_preferencia_canal,priority:2"`
+	Recordatorios bool      `json:"recordatorios" gorm:"not null"` // sin default: GORM cambiaría un false por true al insertar
+	CreatedAt     time.Time `json:"-"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
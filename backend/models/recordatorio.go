@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Recordatorio registra que ya se avisó a un socio de una sesión concreta,
+// para no repetir el envío si el proceso se reinicia.
+type Recordatorio struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	InscripcionID uint      `json:"inscripcion_id" gorm:"not null;uniqueIndex:idx_recordatorio_sesion,priority:1"`
+	Sesion        time.Time `json:"sesion" gorm:"type:datetime;not null;uniqueIndex:idx_recordatorio_sesion,priority:2"`
+	Canal         string    `json:"canal" gorm:"type:varchar(20);not null;uniqueIndex:idx_recordatorio_sesion,priority:3"`
+	UsuarioID     uint      `json:"usuario_id" gorm:"not null;index"`
+	ActividadID   uint      `json:"actividad_id" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
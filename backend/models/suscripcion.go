@@ -0,0 +1,25 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Estados posibles de una suscripción
+const (
+	SuscripcionPendiente = "pendiente"
+	SuscripcionActiva    = "activa"
+	SuscripcionCancelada = "cancelada"
+)
+
+type Suscripcion struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UsuarioID   uint           `json:"usuario_id" gorm:"not null;index"`
+	Plan        string         `json:"plan" gorm:"not null"`
+	Estado      string         `json:"estado" gorm:"type:varchar(20);not null;default:'pendiente'"`
+	FechaInicio *time.Time     `json:"fecha_inicio" gorm:"type:datetime"`
+	FechaFin    *time.Time     `json:"fecha_fin" gorm:"type:datetime"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
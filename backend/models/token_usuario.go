@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Usos de un token de un solo uso enviado por correo
+const (
+	TokenRecuperacion = "recuperacion"
+	TokenVerificacion = "verificacion"
+)
+
+// TokenUsuario es un token de un solo uso que se envía por correo. Sólo se
+// guarda su hash SHA-256: quien lea la base no puede usar los enlaces.
+type TokenUsuario struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UsuarioID uint       `json:"usuario_id" gorm:"not null;index"`
+	Tipo      string     `json:"tipo" gorm:"type:varchar(20);not null"`
+	Hash      string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiraAt  time.Time  `json:"expira_at" gorm:"type:datetime;not null"`
+	UsadoAt   *time.Time `json:"usado_at" gorm:"type:datetime"`
+	CreatedAt time.Time  `json:"created_at"`
+}
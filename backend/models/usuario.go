@@ -5,15 +5,30 @@ import (
 	"time"
 )
 
+// Tipos de usuario
+const (
+	UsuarioSocio         = "socio"
+	UsuarioAdministrador = "administrador"
+)
+
 type Usuario struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	Nombre       string         `json:"nombre" gorm:"not null"`
-	Email        string         `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
-	PasswordHash string         `json:"-" gorm:"not null"`
-	Tipo         string         `json:"tipo" gorm:"not null;default:'socio'"` // socio, administrador
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Nombre            string         `json:"nombre" gorm:"not null"`
+	Email             string         `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
+	PasswordHash      string         `json:"-" gorm:"not null"`
+	Tipo              string         `json:"tipo" gorm:"not null;default:'socio'"` // socio, administrador
+	Idioma            string         `json:"idioma" gorm:"type:varchar(5)"`        // es, en; vacío usa Accept-Language
+	SuspendidoAt      *time.Time     `json:"suspendido_at" gorm:"type:datetime"`
+	SuspendidoPor     *uint          `json:"suspendido_por,omitempty"`
+	MotivoSuspension  string         `json:"motivo_suspension,omitempty"`
+	CalendarioToken   *string        `json:"-" gorm:"type:varchar(64);uniqueIndex"` // secreto de la URL del feed iCal
+	IntentosFallidos  int            `json:"-" gorm:"not null;default:0"`           // logins fallidos seguidos
+	BloqueadoHasta    *time.Time     `json:"bloqueado_hasta,omitempty" gorm:"type:datetime"`
+	EmailVerificadoAt *time.Time     `json:"email_verificado_at" gorm:"type:datetime"`
+	VersionSesion     int            `json:"-" gorm:"not null;default:0"` // al incrementarla se invalidan los JWT emitidos
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relaciones
 	Inscripciones []Inscripcion `json:"inscripciones,omitempty" gorm:"foreignKey:UsuarioID"`
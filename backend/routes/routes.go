@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/controllers"
+	"proyecto-gym-backend/middleware"
+	"proyecto-gym-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registrar agrega todas las rutas del backend al router. Se usa desde
+// main y desde la verificación de la especificación OpenAPI.
+func Registrar(r *gin.Engine) {
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.ErrRutaNoEncontrada)
+	})
+
+	// Health checks y métricas para Prometheus
+	r.GET("/healthz", controllers.Healthz)
+	r.GET("/readyz", controllers.Readyz)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Documentación de la API
+	r.GET("/api/openapi.json", controllers.GetOpenAPI)
+	r.GET("/api/docs", controllers.GetDocs)
+
+	// Versión 1 de la API. /api se mantiene como alias de v1 para los
+	// clientes existentes y puede avisar que será retirado.
+	registrarV1(r.Group("/api/v1"))
+
+	alias := r.Group("/api")
+	if aviso, ok := avisoAlias(); ok {
+		alias.Use(middleware.Deprecado(aviso))
+	}
+	registrarV1(alias)
+}
+
+// registrarV1 agrega las rutas de la versión 1 bajo el grupo recibido.
+func registrarV1(api *gin.RouterGroup) {
+	// Rutas públicas
+	public := api.Group("")
+	{
+		// Autenticación
+		public.POST("/login",
+			middleware.LimitarTasa(services.LimiteLoginIP, middleware.PorIP),
+			middleware.LimitarTasa(services.LimiteLoginCuenta, middleware.PorEmail),
+			controllers.Login)
+		public.POST("/register", controllers.Register)
+		public.POST("/password/forgot",
+			middleware.LimitarTasa(services.LimiteLoginIP, middleware.PorIP),
+			middleware.LimitarTasa(services.LimiteRecuperacion, middleware.PorEmail),
+			controllers.OlvidePassword)
+		public.POST("/password/reset",
+			middleware.LimitarTasa(services.LimiteLoginIP, middleware.PorIP),
+			controllers.RestablecerPassword)
+		public.POST("/email/verify",
+			middleware.LimitarTasa(services.LimiteLoginIP, middleware.PorIP),
+			controllers.VerificarEmail)
+
+		// Actividades (público)
+		public.GET("/actividades", controllers.GetActividades)
+		public.GET("/actividades/:id", controllers.GetActividadByID)
+		public.GET("/horario", middleware.AuthOpcional(), controllers.GetHorario)
+		public.GET("/horario/pdf", controllers.GetHorarioPDF)
+		public.GET("/actividades/disponibilidad", controllers.StreamDisponibilidad)
+
+		// Inscripciones (público)
+		public.GET("/usuarios/:id/inscripciones", controllers.GetInscripcionesUsuario)
+		public.GET("/politica-cancelacion", controllers.GetPoliticaCancelacion)
+
+		// Feeds iCal (el del socio se autentica con el token de la URL)
+		public.GET("/calendario/publico", controllers.GetCalendarioPublico)
+		public.GET("/calendario/socios/:token", controllers.GetCalendarioSocio)
+
+		// Pagos (webhook de la pasarela y checkout simulado)
+		public.GET("/planes", controllers.GetPlanes)
+		public.POST("/pagos/webhook", controllers.PagoWebhook)
+		// El checkout simulado firma webhooks por su cuenta: nunca en producción
+		if config.App.Perfil != config.PerfilProd {
+			public.POST("/pagos/fake/checkout/:referencia", controllers.FakeCheckout)
+		}
+	}
+
+	// Rutas de usuarios autenticados
+	auth := api.Group("")
+	auth.Use(middleware.AuthMiddleware(""))
+	{
+		auth.POST("/password/change",
+			middleware.LimitarTasa(services.LimiteLoginCuenta, middleware.PorUsuario),
+			controllers.CambiarPassword)
+		auth.POST("/email/verify/resend",
+			middleware.LimitarTasa(services.LimiteVerificacion, middleware.PorUsuario),
+			controllers.ReenviarVerificacionEmail)
+
+		auth.POST("/inscripciones", middleware.Idempotencia(), controllers.CreateInscripcion)
+		auth.DELETE("/inscripciones/:id", controllers.DeleteInscripcion)
+
+		auth.POST("/pagos", middleware.Idempotencia(), controllers.CreatePago)
+		auth.GET("/usuarios/:id/pagos", controllers.GetPagosUsuario)
+		auth.GET("/usuarios/:id/facturas", controllers.GetFacturasUsuario)
+		auth.GET("/usuarios/:id/facturas/:facturaId/pdf", controllers.GetFacturaUsuarioPDF)
+		auth.GET("/usuarios/:id/penalizaciones", controllers.GetPenalizacionesUsuario)
+		auth.GET("/usuarios/:id/preferencias", controllers.GetPreferenciasUsuario)
+		auth.PUT("/usuarios/:id/preferencias", controllers.UpdatePreferenciaUsuario)
+		auth.PUT("/usuarios/:id/idioma", controllers.UpdateIdiomaUsuario)
+		auth.GET("/usuarios/:id/calendario", controllers.GetCalendarioUsuario)
+		auth.POST("/usuarios/:id/calendario/regenerar", controllers.RegenerarCalendarioUsuario)
+	}
+
+	// Rutas protegidas (solo administradores)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuthMiddleware("administrador"))
+	{
+		admin.GET("/actividades", controllers.GetActividadesAdmin) // ← NUEVA RUTA CON FILTROS
+		admin.POST("/actividades", controllers.CreateActividad)
+		admin.POST("/actividades/import", controllers.ImportActividades)
+		admin.GET("/actividades/export", controllers.ExportActividades)
+		admin.PUT("/actividades/:id", controllers.UpdateActividad)
+		admin.DELETE("/actividades/:id", controllers.DeleteActividad)
+		admin.GET("/actividades/:id/inscripciones", controllers.GetInscripcionesActividadAdmin)
+		admin.POST("/actividades/:id/inscripciones", middleware.Idempotencia(), controllers.CreateInscripcionAdmin)
+		admin.GET("/actividades/:id/inscripciones/exportar", controllers.ExportarPlanillaActividad)
+
+		admin.GET("/usuarios", controllers.GetUsuariosAdmin)
+		admin.GET("/usuarios/:id", controllers.GetUsuarioAdmin)
+		admin.PUT("/usuarios/:id", controllers.UpdateUsuarioAdmin)
+		admin.DELETE("/usuarios/:id", controllers.DeleteUsuarioAdmin)
+		admin.PUT("/usuarios/:id/tipo", controllers.CambiarTipoUsuario)
+		admin.POST("/usuarios/:id/suspender", controllers.SuspenderUsuario)
+		admin.POST("/usuarios/:id/reactivar", controllers.ReactivarUsuario)
+		admin.POST("/usuarios/:id/restaurar", controllers.RestaurarUsuario)
+		admin.POST("/usuarios/:id/desbloquear", controllers.DesbloquearUsuario)
+		admin.GET("/usuarios/:id/inscripciones", controllers.GetInscripcionesUsuarioAdmin)
+
+		admin.GET("/pagos", controllers.GetPagosAdmin)
+		admin.GET("/facturas", controllers.GetFacturasAdmin)
+		admin.GET("/facturas/:id/pdf", controllers.GetFacturaAdminPDF)
+		admin.POST("/facturas/:id/anular", controllers.AnularFactura)
+
+		admin.DELETE("/inscripciones/:id", controllers.DeleteInscripcionAdmin)
+		admin.POST("/inscripciones/:id/ausencia", controllers.MarcarAusencia)
+		admin.POST("/inscripciones/:id/mover", middleware.Idempotencia(), controllers.MoverInscripcionAdmin)
+		admin.GET("/penalizaciones", controllers.GetPenalizacionesAdmin)
+		admin.POST("/penalizaciones/:id/anular", controllers.AnularPenalizacion)
+
+		admin.GET("/reportes/ocupacion", controllers.GetReporteOcupacion)
+		admin.GET("/reportes/tendencia", controllers.GetReporteTendencia)
+		admin.GET("/reportes/cancelaciones", controllers.GetReporteCancelaciones)
+		admin.GET("/reportes/franjas", controllers.GetReporteFranjas)
+
+		admin.GET("/auditoria", controllers.GetAuditoriaAdmin)
+	}
+}
+
+// avisoAlias arma el aviso de deprecación del alias /api a partir de la
+// configuración. Las fechas ya fueron validadas al cargarla.
+func avisoAlias() (middleware.Deprecacion, bool) {
+	if config.App.API.AliasDeprecacion == "" {
+		return middleware.Deprecacion{}, false
+	}
+
+	aviso := middleware.Deprecacion{Enlace: "/api/docs"}
+	aviso.Desde, _ = time.Parse(config.FormatoFecha, config.App.API.AliasDeprecacion)
+	if config.App.API.AliasSunset != "" {
+		aviso.Sunset, _ = time.Parse(config.FormatoFecha, config.App.API.AliasSunset)
+	}
+	return aviso, true
+}
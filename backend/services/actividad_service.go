@@ -1,10 +1,17 @@
 package services
 
 import (
+	"errors"
+
+	"proyecto-gym-backend/apperrors"
 	"proyecto-gym-backend/config"
 	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
 )
 
+var ErrActividadNoEncontrada = apperrors.NoEncontrado("ACTIVIDAD_NO_ENCONTRADA", "Actividad no encontrada")
+
 func GetActividadesConCupo() ([]models.Actividad, error) {
 	var actividades []models.Actividad
 
@@ -25,6 +32,9 @@ func GetActividadByIDConCupo(id uint) (*models.Actividad, error) {
 	var actividad models.Actividad
 
 	if err := config.DB.First(&actividad, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrActividadNoEncontrada
+		}
 		return nil, err
 	}
 
@@ -34,3 +44,23 @@ func GetActividadByIDConCupo(id uint) (*models.Actividad, error) {
 
 	return &actividad, nil
 }
+
+// NotificarCambioActividad avisa a todos los inscritos que la actividad fue
+// modificada o eliminada.
+func NotificarCambioActividad(tx *gorm.DB, actividad *models.Actividad, eliminada bool) error {
+	var usuarioIDs []uint
+	if err := tx.Model(&models.Inscripcion{}).Where("actividad_id = ?", actividad.ID).
+		Pluck("usuario_id", &usuarioIDs).Error; err != nil {
+		return err
+	}
+
+	for _, usuarioID := range usuarioIDs {
+		if err := EncolarNotificacion(tx, usuarioID, PlantillaActividadModificada, map[string]interface{}{
+			"Actividad": actividad,
+			"Eliminada": eliminada,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"log/slog"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+type FiltroAuditoria struct {
+	Tipo      string
+	UsuarioID uint
+	Pagina    int
+	PorPagina int
+}
+
+// RegistrarAuditoria guarda un evento de seguridad. Un fallo al guardarlo
+// no debe impedir la operación, así que sólo se registra en el log.
+func RegistrarAuditoria(tipo string, usuarioID, actorID *uint, ip string, detalles map[string]interface{}) {
+	evento := models.EventoAuditoria{
+		Tipo:      tipo,
+		UsuarioID: usuarioID,
+		ActorID:   actorID,
+		IP:        ip,
+		Detalles:  detalles,
+	}
+	if err := config.DB.Create(&evento).Error; err != nil {
+		slog.Error("no se pudo registrar el evento de auditoría", "tipo", tipo, "error", err)
+		return
+	}
+	slog.Info("evento de auditoría", "tipo", tipo, "usuario_id", idAuditoria(usuarioID),
+		"actor_id", idAuditoria(actorID), "ip", ip)
+}
+
+// idAuditoria devuelve el ID a registrar en el log, o nil si no hay.
+func idAuditoria(id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// ListarAuditoria devuelve una página de eventos, del más reciente al más antiguo.
+func ListarAuditoria(filtro FiltroAuditoria) ([]models.EventoAuditoria, int64, error) {
+	query := config.DB.Model(&models.EventoAuditoria{})
+	if filtro.Tipo != "" {
+		query = query.Where("tipo = ?", filtro.Tipo)
+	}
+	if filtro.UsuarioID != 0 {
+		query = query.Where("usuario_id = ?", filtro.UsuarioID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var eventos []models.EventoAuditoria
+	if err := query.Order("id DESC").Offset((filtro.Pagina - 1) * filtro.PorPagina).Limit(filtro.PorPagina).
+		Find(&eventos).Error; err != nil {
+		return nil, 0, err
+	}
+	return eventos, total, nil
+}
@@ -4,20 +4,29 @@ import (
 	"crypto/md5"
 	"crypto/sha256"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"proyecto-gym-backend/apperrors"
 	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/i18n"
 	"proyecto-gym-backend/models"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = []byte("proyecto_gym_secreto_jwt_2024")
+var (
+	ErrCredencialesInvalidas = apperrors.NoAutorizado("CREDENCIALES_INVALIDAS", "Credenciales inválidas")
+	ErrEmailRegistrado       = apperrors.Conflicto("EMAIL_REGISTRADO", "El email ya está registrado")
+	ErrUsuarioNoEncontrado   = apperrors.NoEncontrado("USUARIO_NO_ENCONTRADO", "Usuario no encontrado")
+	ErrIdiomaInvalido        = apperrors.Invalido("IDIOMA_INVALIDO", "Idioma no soportado")
+)
 
 type Claims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Tipo   string `json:"tipo"`
+	Sesion int    `json:"sesion"` // VersionSesion del usuario al emitir el token
 	jwt.RegisteredClaims
 }
 
@@ -36,20 +45,21 @@ func GenerateJWT(user models.Usuario) (string, error) {
 		UserID: user.ID,
 		Email:  user.Email,
 		Tipo:   user.Tipo,
+		Sesion: user.VersionSesion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.App.JWT.ExpiracionHoras) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString([]byte(config.App.JWT.Secreto))
 }
 
 func ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		return []byte(config.App.JWT.Secreto), nil
 	})
 
 	if err != nil {
@@ -63,18 +73,41 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// GuardarIdiomaUsuario cambia el idioma preferido. Un idioma vacío vuelve a
+// usar el que pida el cliente en Accept-Language.
+func GuardarIdiomaUsuario(usuarioID uint, idioma string) error {
+	if idioma != "" && !i18n.Soportado(idioma) {
+		return ErrIdiomaInvalido
+	}
+
+	resultado := config.DB.Model(&models.Usuario{}).Where("id = ?", usuarioID).Update("idioma", idioma)
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		var count int64
+		config.DB.Model(&models.Usuario{}).Where("id = ?", usuarioID).Count(&count)
+		if count == 0 {
+			return ErrUsuarioNoEncontrado
+		}
+	}
+	return nil
+}
+
 func CreateDefaultAdmin() {
 	var count int64
 	config.DB.Model(&models.Usuario{}).Where("tipo = ?", "administrador").Count(&count)
 
 	if count == 0 {
+		ahora := time.Now()
 		admin := models.Usuario{
-			Nombre:       "Administrador",
-			Email:        "leoneladmin@gmail.com",
-			PasswordHash: HashPasswordSHA256("LeoGamer386"),
-			Tipo:         "administrador",
+			Nombre:            "Administrador",
+			Email:             "leoneladmin@gmail.com",
+			PasswordHash:      HashPasswordSHA256("LeoGamer386"),
+			Tipo:              "administrador",
+			EmailVerificadoAt: &ahora,
 		}
 		config.DB.Create(&admin)
-		fmt.Println("✅ Usuario administrador creado: admin@proyecto-gym.com / admin123")
+		slog.Info("usuario administrador por defecto creado", "usuario_id", admin.ID)
 	}
 }
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrCalendarioNoEncontrado = apperrors.NoEncontrado("CALENDARIO_NO_ENCONTRADO", "Calendario no encontrado")
+
+// Días de la semana en el formato BYDAY de las reglas de recurrencia
+var diasICal = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// ZonaHorariaCalendario es la zona horaria en la que se dictan las clases.
+// La configuración ya se validó al cargarla.
+func ZonaHorariaCalendario() *time.Location {
+	zona, err := time.LoadLocation(config.App.Calendario.ZonaHoraria)
+	if err != nil {
+		return time.Local
+	}
+	return zona
+}
+
+// TokenCalendario devuelve el secreto de la URL del feed del usuario,
+// creándolo la primera vez. Con regenerar se invalida la URL anterior.
+func TokenCalendario(usuarioID uint, regenerar bool) (string, error) {
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, usuarioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUsuarioNoEncontrado
+		}
+		return "", err
+	}
+
+	if usuario.CalendarioToken != nil && !regenerar {
+		return *usuario.CalendarioToken, nil
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	if err := config.DB.Model(&usuario).Update("calendario_token", token).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CalendarioUsuario arma el feed iCal con las clases en las que está inscrito
+// el dueño del token.
+func CalendarioUsuario(token string) ([]byte, error) {
+	var usuario models.Usuario
+	if err := config.DB.Where("calendario_token = ? AND suspendido_at IS NULL", token).
+		First(&usuario).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarioNoEncontrado
+		}
+		return nil, err
+	}
+
+	var inscripciones []models.Inscripcion
+	if err := config.DB.Preload("Actividad").Where("usuario_id = ?", usuario.ID).
+		Find(&inscripciones).Error; err != nil {
+		return nil, err
+	}
+
+	cal := nuevoCalendario("Mis clases del gimnasio")
+	for _, inscripcion := range inscripciones {
+		if inscripcion.Actividad.ID == 0 {
+			continue
+		}
+		desde := inscripcion.CreatedAt
+		if inscripcion.FechaInscripcion != nil {
+			desde = *inscripcion.FechaInscripcion
+		}
+		cal.evento(fmt.Sprintf("inscripcion-%d", inscripcion.ID), &inscripcion.Actividad, desde)
+	}
+	return cal.bytes(), nil
+}
+
+// CalendarioPublico arma el feed iCal con todo el cronograma, opcionalmente
+// filtrado por categoría o profesor.
+func CalendarioPublico(categoria, profesor string) ([]byte, error) {
+	query := config.DB.Order("id")
+	if categoria != "" {
+		query = query.Where("categoria = ?", categoria)
+	}
+	if profesor != "" {
+		query = query.Where("profesor = ?", profesor)
+	}
+
+	var actividades []models.Actividad
+	if err := query.Find(&actividades).Error; err != nil {
+		return nil, err
+	}
+
+	cal := nuevoCalendario("Cronograma del gimnasio")
+	for i := range actividades {
+		cal.evento(fmt.Sprintf("actividad-%d", actividades[i].ID), &actividades[i], actividades[i].CreatedAt)
+	}
+	return cal.bytes(), nil
+}
+
+// calendario escribe un VCALENDAR (RFC 5545) con un evento semanal por clase.
+type calendario struct {
+	buf  bytes.Buffer
+	zona *time.Location
+}
+
+func nuevoCalendario(nombre string) *calendario {
+	cal := &calendario{zona: ZonaHorariaCalendario()}
+	cal.linea("BEGIN", "VCALENDAR")
+	cal.linea("VERSION", "2.0")
+	cal.linea("PRODID", "-//Proyecto Gym//Cronograma//ES")
+	cal.linea("CALSCALE", "GREGORIAN")
+	cal.linea("METHOD", "PUBLISH")
+	cal.linea("X-WR-CALNAME", escaparTexto(nombre))
+	cal.linea("X-WR-TIMEZONE", cal.zona.String())
+	cal.zonaHoraria()
+	return cal
+}
+
+// evento agrega la clase semanal de la actividad a partir de su primera
+// sesión posterior a desde. Las actividades sin día y hora fijos se omiten.
+func (cal *calendario) evento(uid string, actividad *models.Actividad, desde time.Time) {
+	dia, ok := diasSemana[actividad.Dia]
+	if !ok {
+		return
+	}
+	inicio, ok := ProximaSesion(actividad, desde.In(cal.zona))
+	if !ok {
+		return
+	}
+
+	descripcion := actividad.Descripcion
+	if actividad.Profesor != "" {
+		descripcion = strings.TrimSpace(descripcion + "\nProfesor: " + actividad.Profesor)
+	}
+
+	cal.linea("BEGIN", "VEVENT")
+	cal.linea("UID", uid+"@proyecto-gym")
+	cal.linea("DTSTAMP", actividad.UpdatedAt.UTC().Format("20060102T150405Z"))
+	cal.linea("LAST-MODIFIED", actividad.UpdatedAt.UTC().Format("20060102T150405Z"))
+	cal.linea("DTSTART;TZID="+cal.zona.String(), inicio.Format("20060102T150405"))
+	cal.linea("DURATION", "PT"+strconv.Itoa(actividad.DuracionMinutos)+"M")
+	cal.linea("RRULE", "FREQ=WEEKLY;BYDAY="+diasICal[dia])
+	cal.linea("SUMMARY", escaparTexto(actividad.Titulo))
+	if descripcion != "" {
+		cal.linea("DESCRIPTION", escaparTexto(descripcion))
+	}
+	if actividad.Categoria != "" {
+		cal.linea("CATEGORIES", escaparTexto(actividad.Categoria))
+	}
+	cal.linea("END", "VEVENT")
+}
+
+// zonaHoraria escribe el VTIMEZONE de la zona con los cambios de horario del
+// año en curso. Las zonas sin horario de verano tienen un único período.
+func (cal *calendario) zonaHoraria() {
+	anio := time.Now().In(cal.zona).Year()
+	inicio := time.Date(anio, 1, 1, 0, 0, 0, 0, cal.zona)
+	fin := inicio.AddDate(1, 0, 0)
+
+	cal.linea("BEGIN", "VTIMEZONE")
+	cal.linea("TZID", cal.zona.String())
+
+	nombre, offset := inicio.Zone()
+	cambios := 0
+	for t := inicio; t.Before(fin); t = t.Add(time.Hour) {
+		nuevoNombre, nuevoOffset := t.Zone()
+		if nuevoOffset == offset {
+			continue
+		}
+		// Buscar el segundo exacto del cambio dentro de la última hora
+		antes, despues := t.Add(-time.Hour), t
+		for despues.Sub(antes) > time.Second {
+			medio := antes.Add(despues.Sub(antes) / 2)
+			if _, o := medio.Zone(); o == offset {
+				antes = medio
+			} else {
+				despues = medio
+			}
+		}
+		tipo := "STANDARD"
+		if t.IsDST() {
+			tipo = "DAYLIGHT"
+		}
+		cal.periodo(tipo, despues, offset, nuevoOffset, nuevoNombre)
+		nombre, offset = nuevoNombre, nuevoOffset
+		cambios++
+	}
+	if cambios == 0 {
+		cal.periodo("STANDARD", time.Date(1970, 1, 1, 0, 0, 0, 0, cal.zona), offset, offset, nombre)
+	}
+
+	cal.linea("END", "VTIMEZONE")
+}
+
+// periodo escribe un STANDARD o DAYLIGHT. DTSTART va en la hora local
+// vigente antes del cambio.
+func (cal *calendario) periodo(tipo string, desde time.Time, offsetAnterior, offset int, nombre string) {
+	cal.linea("BEGIN", tipo)
+	cal.linea("DTSTART", desde.In(time.FixedZone("", offsetAnterior)).Format("20060102T150405"))
+	cal.linea("TZOFFSETFROM", formatearOffset(offsetAnterior))
+	cal.linea("TZOFFSETTO", formatearOffset(offset))
+	cal.linea("TZNAME", nombre)
+	cal.linea("END", tipo)
+}
+
+func (cal *calendario) bytes() []byte {
+	cal.linea("END", "VCALENDAR")
+	return cal.buf.Bytes()
+}
+
+// linea escribe una propiedad plegando las líneas de más de 75 bytes como
+// pide el RFC 5545, sin cortar caracteres UTF-8 a la mitad.
+func (cal *calendario) linea(nombre, valor string) {
+	linea := nombre + ":" + valor
+	limite := 75
+	for len(linea) > limite {
+		corte := limite
+		for corte > 0 && !utf8Inicio(linea[corte]) {
+			corte--
+		}
+		cal.buf.WriteString(linea[:corte] + "\r\n ")
+		linea = linea[corte:]
+		limite = 74 // las líneas de continuación empiezan con un espacio
+	}
+	cal.buf.WriteString(linea + "\r\n")
+}
+
+func utf8Inicio(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escaparTexto(texto string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(texto)
+}
+
+func formatearOffset(segundos int) string {
+	signo := "+"
+	if segundos < 0 {
+		signo = "-"
+		segundos = -segundos
+	}
+	return fmt.Sprintf("%s%02d%02d", signo, segundos/3600, segundos%3600/60)
+}
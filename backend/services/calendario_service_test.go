@@ -0,0 +1,173 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"proyecto-gym-backend/models"
+)
+
+func TestCalendarioLineaPliega(t *testing.T) {
+	casos := []struct {
+		nombre string
+		valor  string
+	}{
+		{"corta", "Yoga"},
+		{"justo 75 bytes", strings.Repeat("a", 75-len("SUMMARY:"))},
+		{"76 bytes", strings.Repeat("a", 76-len("SUMMARY:"))},
+		{"varias continuaciones", strings.Repeat("abcdefghij", 30)},
+		{"multibyte en el corte", strings.Repeat("ñ", 100)},
+		{"emoji", strings.Repeat("a", 66) + strings.Repeat("🏋️", 20)},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			var cal calendario
+			cal.linea("SUMMARY", caso.valor)
+			salida := cal.buf.String()
+
+			if !strings.HasSuffix(salida, "\r\n") {
+				t.Fatalf("la propiedad no termina en CRLF: %q", salida)
+			}
+			lineas := strings.Split(strings.TrimSuffix(salida, "\r\n"), "\r\n")
+			for i, linea := range lineas {
+				if len(linea) > 75 {
+					t.Errorf("línea %d de %d bytes: %q", i, len(linea), linea)
+				}
+				if i > 0 && !strings.HasPrefix(linea, " ") {
+					t.Errorf("la continuación %d no empieza con espacio: %q", i, linea)
+				}
+				if !utf8.ValidString(linea) {
+					t.Errorf("la línea %d corta un carácter UTF-8: %q", i, linea)
+				}
+			}
+			if desplegada := strings.ReplaceAll(strings.TrimSuffix(salida, "\r\n"), "\r\n ", ""); desplegada != "SUMMARY:"+caso.valor {
+				t.Errorf("al desplegar se obtiene %q", desplegada)
+			}
+		})
+	}
+}
+
+func TestEscaparTexto(t *testing.T) {
+	casos := []struct{ texto, esperado string }{
+		{"Yoga", "Yoga"},
+		{"Fuerza, resistencia; técnica", `Fuerza\, resistencia\; técnica`},
+		{`C:\sala`, `C:\\sala`},
+		{"Primera línea\nSegunda\r\nTercera", `Primera línea\nSegunda\nTercera`},
+	}
+	for _, caso := range casos {
+		if got := escaparTexto(caso.texto); got != caso.esperado {
+			t.Errorf("escaparTexto(%q) = %q, se esperaba %q", caso.texto, got, caso.esperado)
+		}
+	}
+}
+
+func TestFormatearOffset(t *testing.T) {
+	casos := []struct {
+		segundos int
+		esperado string
+	}{
+		{0, "+0000"},
+		{-3 * 3600, "-0300"},
+		{2 * 3600, "+0200"},
+		{5*3600 + 30*60, "+0530"},
+		{-(9*3600 + 30*60), "-0930"},
+	}
+	for _, caso := range casos {
+		if got := formatearOffset(caso.segundos); got != caso.esperado {
+			t.Errorf("formatearOffset(%d) = %q, se esperaba %q", caso.segundos, got, caso.esperado)
+		}
+	}
+}
+
+func TestCalendarioZonaHoraria(t *testing.T) {
+	casos := []struct {
+		zona      string
+		esperados []string // expresiones que deben aparecer en orden dentro del VTIMEZONE
+	}{
+		{"America/Argentina/Cordoba", []string{
+			`BEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nTZOFFSETFROM:-0300\r\nTZOFFSETTO:-0300\r\nTZNAME:-03\r\nEND:STANDARD`,
+		}},
+		{"Europe/Madrid", []string{
+			`BEGIN:DAYLIGHT\r\nDTSTART:\d{4}03\d{2}T020000\r\nTZOFFSETFROM:\+0100\r\nTZOFFSETTO:\+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT`,
+			`BEGIN:STANDARD\r\nDTSTART:\d{4}10\d{2}T030000\r\nTZOFFSETFROM:\+0200\r\nTZOFFSETTO:\+0100\r\nTZNAME:CET\r\nEND:STANDARD`,
+		}},
+		{"UTC", []string{
+			`BEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nTZOFFSETFROM:\+0000\r\nTZOFFSETTO:\+0000\r\nTZNAME:UTC\r\nEND:STANDARD`,
+		}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.zona, func(t *testing.T) {
+			zona, err := time.LoadLocation(caso.zona)
+			if err != nil {
+				t.Skipf("zona horaria no disponible: %v", err)
+			}
+			cal := &calendario{zona: zona}
+			cal.zonaHoraria()
+			salida := cal.buf.String()
+
+			patron := `(?s)^BEGIN:VTIMEZONE\r\nTZID:` + regexp.QuoteMeta(caso.zona) + `\r\n` +
+				strings.Join(caso.esperados, `\r\n`) + `\r\nEND:VTIMEZONE\r\n$`
+			if !regexp.MustCompile(patron).MatchString(salida) {
+				t.Errorf("VTIMEZONE inesperado:\n%s", salida)
+			}
+		})
+	}
+}
+
+func TestCalendarioEvento(t *testing.T) {
+	zona, err := time.LoadLocation("America/Argentina/Cordoba")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Miércoles 15 de mayo de 2024, 21:00 en UTC (18:00 en Córdoba)
+	desde := time.Date(2024, time.May, 15, 21, 0, 0, 0, time.UTC)
+	actualizada := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	casos := []struct {
+		nombre    string
+		actividad models.Actividad
+		esperadas []string
+	}{
+		{"clase semanal", models.Actividad{Titulo: "Yoga, nivel 1", Dia: "Lunes", Horario: "19:30", DuracionMinutos: 45,
+			Profesor: "Ana", Categoria: "Bienestar", UpdatedAt: actualizada}, []string{
+			"BEGIN:VEVENT",
+			"UID:actividad-7@proyecto-gym",
+			"DTSTAMP:20240501T120000Z",
+			"DTSTART;TZID=America/Argentina/Cordoba:20240520T193000",
+			"DURATION:PT45M",
+			"RRULE:FREQ=WEEKLY;BYDAY=MO",
+			`SUMMARY:Yoga\, nivel 1`,
+			"DESCRIPTION:Profesor: Ana",
+			"CATEGORIES:Bienestar",
+			"END:VEVENT",
+		}},
+		{"la sesión de hoy se toma en la hora local", models.Actividad{Titulo: "Spinning", Dia: "Miércoles", Horario: "20:00",
+			DuracionMinutos: 60, UpdatedAt: actualizada}, []string{
+			"DTSTART;TZID=America/Argentina/Cordoba:20240515T200000",
+			"RRULE:FREQ=WEEKLY;BYDAY=WE",
+		}},
+		{"horario libre se omite", models.Actividad{Titulo: "Sala", Dia: "Lunes", Horario: "Horario Libre"}, nil},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			cal := &calendario{zona: zona}
+			cal.evento("actividad-7", &caso.actividad, desde)
+			salida := cal.buf.String()
+
+			if caso.esperadas == nil {
+				if salida != "" {
+					t.Errorf("no se esperaba ningún evento:\n%s", salida)
+				}
+				return
+			}
+			for _, esperada := range caso.esperadas {
+				if !strings.Contains(salida, esperada+"\r\n") {
+					t.Errorf("falta %q en:\n%s", esperada, salida)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Modos de la política de cancelación
+const (
+	CancelacionPenalizar = "penalizar" // permite la baja tardía pero la registra
+	CancelacionBloquear  = "bloquear"  // rechaza la baja dentro del plazo
+)
+
+var (
+	ErrInscripcionNoEncontrada  = apperrors.NoEncontrado("INSCRIPCION_NO_ENCONTRADA", "Inscripción no encontrada")
+	ErrCancelacionFueraDePlazo  = apperrors.Conflicto("CANCELACION_FUERA_DE_PLAZO", "No se puede cancelar la inscripción con tan poca anticipación")
+	ErrPenalizacionNoEncontrada = apperrors.NoEncontrado("PENALIZACION_NO_ENCONTRADA", "Penalización no encontrada")
+	ErrPenalizacionAnulada      = apperrors.Conflicto("PENALIZACION_ANULADA", "La penalización ya está anulada")
+	ErrAusenciaYaRegistrada     = apperrors.Conflicto("AUSENCIA_YA_REGISTRADA", "La ausencia a esta clase ya fue registrada")
+	ErrReservasSuspendidas      = apperrors.Prohibido("RESERVAS_SUSPENDIDAS", "Tus reservas están suspendidas")
+)
+
+type PoliticaCancelacion struct {
+	HorasLimite    int    `json:"horas_limite"`
+	Modo           string `json:"modo"`
+	MaxFaltas      int    `json:"max_faltas"`
+	VentanaDias    int    `json:"ventana_dias"`
+	SuspensionDias int    `json:"suspension_dias"`
+}
+
+func GetPoliticaCancelacion() PoliticaCancelacion {
+	cfg := config.App.Cancelacion
+	return PoliticaCancelacion{
+		HorasLimite:    cfg.HorasLimite,
+		Modo:           cfg.Modo,
+		MaxFaltas:      cfg.MaxFaltas,
+		VentanaDias:    cfg.VentanaDias,
+		SuspensionDias: cfg.SuspensionDias,
+	}
+}
+
+// EsCancelacionTardia indica si dar de baja la inscripción en este momento
+// cae dentro del plazo en que la política no permite cancelar sin consecuencias.
+func EsCancelacionTardia(actividad *models.Actividad, ahora time.Time) (bool, time.Time) {
+	sesion, ok := ProximaSesion(actividad, ahora)
+	if !ok {
+		return false, time.Time{}
+	}
+	limite := sesion.Add(-time.Duration(GetPoliticaCancelacion().HorasLimite) * time.Hour)
+	return !ahora.Before(limite), sesion
+}
+
+// CancelarInscripcion da de baja una inscripción aplicando la política de
+// cancelación. Devuelve la penalización registrada si la baja fue tardía.
+func CancelarInscripcion(inscripcion *models.Inscripcion) (*models.Penalizacion, error) {
+	politica := GetPoliticaCancelacion()
+	tardia, sesion := EsCancelacionTardia(&inscripcion.Actividad, time.Now())
+
+	if tardia && politica.Modo == CancelacionBloquear {
+		return nil, ErrCancelacionFueraDePlazo.ConDetalle("horas_limite", politica.HorasLimite)
+	}
+
+	var penalizacion *models.Penalizacion
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if tardia {
+			p, err := registrarPenalizacion(tx, inscripcion, models.PenalizacionCancelacionTardia, sesion)
+			if err != nil {
+				return err
+			}
+			penalizacion = p
+		}
+		if err := tx.Delete(inscripcion).Error; err != nil {
+			return err
+		}
+		return EncolarNotificacion(tx, inscripcion.UsuarioID, PlantillaInscripcionCancelada, map[string]interface{}{
+			"Actividad":    inscripcion.Actividad,
+			"Penalizacion": penalizacion,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	PublicarCupo(inscripcion.ActividadID)
+	if tardia {
+		metrics.InscripcionesCanceladas.WithLabelValues(metrics.CancelacionTardia).Inc()
+	} else {
+		metrics.InscripcionesCanceladas.WithLabelValues(metrics.CancelacionSocio).Inc()
+	}
+	return penalizacion, nil
+}
+
+// RegistrarAusencia marca que el socio no asistió a la última clase. Cada
+// sesión admite una sola ausencia por inscripción.
+func RegistrarAusencia(inscripcion *models.Inscripcion) (*models.Penalizacion, error) {
+	sesion, ok := UltimaSesion(&inscripcion.Actividad, time.Now())
+	if !ok {
+		// Sin horario fijo la sesión es el día de hoy
+		ahora := time.Now()
+		sesion = time.Date(ahora.Year(), ahora.Month(), ahora.Day(), 0, 0, 0, 0, ahora.Location())
+	}
+
+	var penalizacion *models.Penalizacion
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var registradas int64
+		if err := tx.Model(&models.Penalizacion{}).
+			Where("inscripcion_id = ? AND tipo = ? AND sesion = ? AND estado = ?",
+				inscripcion.ID, models.PenalizacionAusencia, sesion, models.PenalizacionActiva).
+			Count(&registradas).Error; err != nil {
+			return err
+		}
+		if registradas > 0 {
+			return ErrAusenciaYaRegistrada.ConDetalle("sesion", sesion)
+		}
+
+		p, err := registrarPenalizacion(tx, inscripcion, models.PenalizacionAusencia, sesion)
+		penalizacion = p
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return penalizacion, nil
+}
+
+func registrarPenalizacion(tx *gorm.DB, inscripcion *models.Inscripcion, tipo string, sesion time.Time) (*models.Penalizacion, error) {
+	politica := GetPoliticaCancelacion()
+
+	penalizacion := models.Penalizacion{
+		UsuarioID:     inscripcion.UsuarioID,
+		ActividadID:   inscripcion.ActividadID,
+		InscripcionID: inscripcion.ID,
+		Tipo:          tipo,
+		Sesion:        &sesion,
+		Estado:        models.PenalizacionActiva,
+	}
+
+	// Si con esta falta se alcanza el máximo dentro de la ventana, se suspenden las reservas
+	var faltas int64
+	desde := time.Now().AddDate(0, 0, -politica.VentanaDias)
+	tx.Model(&models.Penalizacion{}).
+		Where("usuario_id = ? AND estado = ? AND created_at >= ?", inscripcion.UsuarioID, models.PenalizacionActiva, desde).
+		Count(&faltas)
+	if politica.MaxFaltas > 0 && int(faltas)+1 >= politica.MaxFaltas {
+		hasta := time.Now().AddDate(0, 0, politica.SuspensionDias)
+		penalizacion.SuspensionHasta = &hasta
+	}
+
+	if err := tx.Create(&penalizacion).Error; err != nil {
+		return nil, err
+	}
+	return &penalizacion, nil
+}
+
+// SuspensionVigente devuelve hasta cuándo el usuario tiene las reservas suspendidas.
+func SuspensionVigente(usuarioID uint) (*time.Time, bool) {
+	var penalizacion models.Penalizacion
+	err := config.DB.
+		Where("usuario_id = ? AND estado = ? AND suspension_hasta > ?", usuarioID, models.PenalizacionActiva, time.Now()).
+		Order("suspension_hasta DESC").First(&penalizacion).Error
+	if err != nil {
+		return nil, false
+	}
+	return penalizacion.SuspensionHasta, true
+}
+
+// AnularPenalizacion permite a un administrador dejar sin efecto una
+// penalización (y la suspensión que hubiera generado).
+func AnularPenalizacion(id uint, adminID uint, motivo string) (*models.Penalizacion, error) {
+	var penalizacion models.Penalizacion
+	if err := config.DB.First(&penalizacion, id).Error; err != nil {
+		return nil, ErrPenalizacionNoEncontrada
+	}
+
+	if penalizacion.Estado == models.PenalizacionAnulada {
+		return nil, ErrPenalizacionAnulada
+	}
+
+	penalizacion.Estado = models.PenalizacionAnulada
+	penalizacion.AnuladaPor = &adminID
+	penalizacion.MotivoAnulacion = motivo
+	if err := config.DB.Save(&penalizacion).Error; err != nil {
+		return nil, err
+	}
+
+	return &penalizacion, nil
+}
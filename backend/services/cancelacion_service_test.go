@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestRegistrarAusenciaUnaVezPorSesion(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		horario string
+	}{
+		{"clase con horario fijo", "18:00"},
+		{"actividad en horario libre", "Horario Libre"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			actividad := nuevaActividad("Spinning", "Martes", caso.horario, 10)
+			socio := nuevoUsuario("socio@gym.test")
+			crear(t, actividad, socio)
+			inscripcion := &models.Inscripcion{UsuarioID: socio.ID, ActividadID: actividad.ID}
+			crear(t, inscripcion)
+			inscripcion.Actividad = *actividad
+
+			if _, err := RegistrarAusencia(inscripcion); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := RegistrarAusencia(inscripcion); !errors.Is(err, ErrAusenciaYaRegistrada) {
+				t.Fatalf("segunda ausencia: err = %v, se esperaba %v", err, ErrAusenciaYaRegistrada)
+			}
+
+			var penalizaciones int64
+			config.DB.Model(&models.Penalizacion{}).Where("inscripcion_id = ?", inscripcion.ID).Count(&penalizaciones)
+			if penalizaciones != 1 {
+				t.Errorf("penalizaciones = %d, se esperaba 1", penalizaciones)
+			}
+			if _, suspendido := SuspensionVigente(socio.ID); suspendido {
+				t.Error("una sola ausencia no debería suspender las reservas")
+			}
+		})
+	}
+}
+
+func TestRegistrarAusenciaTrasAnularPenalizacion(t *testing.T) {
+	prepararDB(t)
+	actividad := nuevaActividad("Spinning", "Martes", "18:00", 10)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, actividad, socio)
+	inscripcion := &models.Inscripcion{UsuarioID: socio.ID, ActividadID: actividad.ID, Actividad: *actividad}
+	crear(t, inscripcion)
+
+	penalizacion, err := RegistrarAusencia(inscripcion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AnularPenalizacion(penalizacion.ID, 1, "estuvo presente"); err != nil {
+		t.Fatal(err)
+	}
+	// Anulada la anterior, la ausencia se puede volver a registrar
+	if _, err := RegistrarAusencia(inscripcion); err != nil {
+		t.Errorf("err = %v, se esperaba registrar la ausencia", err)
+	}
+}
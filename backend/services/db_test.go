@@ -0,0 +1,129 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var registrarDriverTest sync.Once
+
+// prepararDB reemplaza config.DB por una base SQLite en memoria con el
+// esquema migrado, de modo que las consultas se prueben contra las mismas
+// tablas que crea la aplicación. Al terminar el test se restaura la anterior.
+func prepararDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	// SQLite no tiene DATE_FORMAT de MySQL; se registra lo que usan los reportes
+	registrarDriverTest.Do(func() {
+		sql.Register("sqlite3_gym", &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				return conn.RegisterFunc("DATE_FORMAT", dateFormatMySQL, true)
+			},
+		})
+	})
+
+	db, err := gorm.Open(&sqlite.Dialector{DriverName: "sqlite3_gym", DSN: ":memory:"},
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cada conexión a :memory: es una base distinta
+	sqlDB.SetMaxOpenConns(1)
+
+	anterior := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = anterior
+		sqlDB.Close()
+	})
+
+	if err := config.Migrar(models.Todos()...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// dateFormatMySQL implementa los especificadores de DATE_FORMAT que usa el backend.
+func dateFormatMySQL(valor interface{}, formato string) (string, error) {
+	var fecha time.Time
+	switch v := valor.(type) {
+	case time.Time:
+		fecha = v
+	case string:
+		var err error
+		fecha, err = parsearFechaSQLite(v)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("DATE_FORMAT: valor no soportado %T", valor)
+	}
+
+	anioISO, semana := fecha.ISOWeek()
+	reemplazos := strings.NewReplacer(
+		"%Y", fmt.Sprintf("%04d", fecha.Year()),
+		"%m", fmt.Sprintf("%02d", fecha.Month()),
+		"%d", fmt.Sprintf("%02d", fecha.Day()),
+		"%x", fmt.Sprintf("%04d", anioISO),
+		"%v", fmt.Sprintf("%02d", semana),
+	)
+	return reemplazos.Replace(formato), nil
+}
+
+func parsearFechaSQLite(valor string) (time.Time, error) {
+	for _, formato := range sqlite3.SQLiteTimestampFormats {
+		if fecha, err := time.Parse(formato, valor); err == nil {
+			return fecha, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("DATE_FORMAT: fecha inválida %q", valor)
+}
+
+// crear inserta los registros de prueba y corta el test si falla.
+func crear(t *testing.T, registros ...interface{}) {
+	t.Helper()
+	for _, registro := range registros {
+		if err := config.DB.Create(registro).Error; err != nil {
+			t.Fatalf("creando %T: %v", registro, err)
+		}
+	}
+}
+
+func nuevaActividad(titulo, dia, horario string, cupo int) *models.Actividad {
+	return &models.Actividad{
+		Titulo:          titulo,
+		Categoria:       "General",
+		Dia:             dia,
+		Horario:         horario,
+		DuracionMinutos: 60,
+		CupoMaximo:      cupo,
+		Profesor:        "Profe",
+	}
+}
+
+func nuevoUsuario(email string) *models.Usuario {
+	ahora := time.Now()
+	return &models.Usuario{
+		Nombre:            "Socio",
+		Email:             email,
+		PasswordHash:      HashPasswordSHA256("secreto"),
+		Tipo:              models.UsuarioSocio,
+		EmailVerificadoAt: &ahora,
+	}
+}
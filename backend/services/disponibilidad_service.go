@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+// EventoCupo informa el cupo de una actividad después de un alta o baja.
+type EventoCupo struct {
+	ActividadID    uint      `json:"actividad_id"`
+	CupoMaximo     int       `json:"cupo_maximo"`
+	Inscritos      int       `json:"inscritos"`
+	CupoDisponible int       `json:"cupo_disponible"`
+	Momento        time.Time `json:"momento"`
+}
+
+// BrokerCupos reparte los eventos de cupo entre los suscriptores. El hub en
+// memoria alcanza con una instancia; con varias se registra un broker
+// externo (Redis, NATS...) que reenvíe los eventos de las demás.
+type BrokerCupos interface {
+	Publicar(evento EventoCupo)
+	// Suscribir devuelve un canal con todos los eventos y la función para
+	// dejar de recibirlos. El canal se cierra al desuscribirse o al cerrar el broker.
+	Suscribir() (<-chan EventoCupo, func())
+	Cerrar()
+}
+
+var brokersCupos = map[string]func() BrokerCupos{
+	"memoria": func() BrokerCupos {
+		return NewHubCupos()
+	},
+}
+
+var brokerCupos BrokerCupos = NewHubCupos()
+
+// RegistrarBrokerCupos permite agregar brokers externos sin tocar el servicio.
+func RegistrarBrokerCupos(nombre string, constructor func() BrokerCupos) {
+	brokersCupos[nombre] = constructor
+}
+
+func InitDisponibilidad() error {
+	nombre := config.App.Disponibilidad.Broker
+	constructor, ok := brokersCupos[nombre]
+	if !ok {
+		return fmt.Errorf("broker de disponibilidad desconocido: %s", nombre)
+	}
+	brokerCupos = constructor()
+	return nil
+}
+
+func SuscribirCupos() (<-chan EventoCupo, func()) {
+	return brokerCupos.Suscribir()
+}
+
+// CerrarDisponibilidad corta las suscripciones abiertas para que los streams
+// no demoren el apagado del servidor.
+func CerrarDisponibilidad() {
+	brokerCupos.Cerrar()
+}
+
+// PublicarCupo recalcula el cupo de las actividades y lo publica. Se llama
+// después de confirmar la transacción que dio de alta o baja inscripciones.
+func PublicarCupo(actividadIDs ...uint) {
+	eventos, err := CuposActividades(actividadIDs)
+	if err != nil {
+		slog.Error("no se pudo publicar el cupo", "actividades", actividadIDs, "error", err)
+		return
+	}
+	for _, evento := range eventos {
+		brokerCupos.Publicar(evento)
+	}
+}
+
+// CuposActividades devuelve el cupo actual de las actividades indicadas, o
+// de todas si no se indica ninguna.
+func CuposActividades(actividadIDs []uint) ([]EventoCupo, error) {
+	query := config.DB.Model(&models.Actividad{}).Order("id")
+	if len(actividadIDs) > 0 {
+		query = query.Where("id IN ?", actividadIDs)
+	}
+	var actividades []models.Actividad
+	if err := query.Select("id", "cupo_maximo").Find(&actividades).Error; err != nil {
+		return nil, err
+	}
+
+	var conteos []struct {
+		ActividadID uint
+		Total       int
+	}
+	conteo := config.DB.Model(&models.Inscripcion{}).Select("actividad_id, COUNT(*) AS total").Group("actividad_id")
+	if len(actividadIDs) > 0 {
+		conteo = conteo.Where("actividad_id IN ?", actividadIDs)
+	}
+	if err := conteo.Scan(&conteos).Error; err != nil {
+		return nil, err
+	}
+	inscritos := map[uint]int{}
+	for _, c := range conteos {
+		inscritos[c.ActividadID] = c.Total
+	}
+
+	ahora := time.Now()
+	eventos := make([]EventoCupo, len(actividades))
+	for i, actividad := range actividades {
+		eventos[i] = EventoCupo{
+			ActividadID:    actividad.ID,
+			CupoMaximo:     actividad.CupoMaximo,
+			Inscritos:      inscritos[actividad.ID],
+			CupoDisponible: actividad.CupoMaximo - inscritos[actividad.ID],
+			Momento:        ahora,
+		}
+	}
+	return eventos, nil
+}
+
+// Eventos que puede acumular un suscriptor lento antes de empezar a perderlos
+const bufferSuscriptorCupos = 32
+
+// HubCupos es el broker en memoria: entrega cada evento a todos los
+// suscriptores del proceso sin bloquear al que publica.
+type HubCupos struct {
+	mu           sync.Mutex
+	suscriptores map[chan EventoCupo]struct{}
+	cerrado      bool
+}
+
+func NewHubCupos() *HubCupos {
+	return &HubCupos{suscriptores: map[chan EventoCupo]struct{}{}}
+}
+
+func (h *HubCupos) Publicar(evento EventoCupo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for canal := range h.suscriptores {
+		select {
+		case canal <- evento:
+		default:
+			// El cliente no da abasto; el próximo evento de la actividad lo corrige
+		}
+	}
+}
+
+func (h *HubCupos) Suscribir() (<-chan EventoCupo, func()) {
+	canal := make(chan EventoCupo, bufferSuscriptorCupos)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cerrado {
+		close(canal)
+		return canal, func() {}
+	}
+	h.suscriptores[canal] = struct{}{}
+
+	return canal, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.suscriptores[canal]; ok {
+			delete(h.suscriptores, canal)
+			close(canal)
+		}
+	}
+}
+
+func (h *HubCupos) Cerrar() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cerrado = true
+	for canal := range h.suscriptores {
+		delete(h.suscriptores, canal)
+		close(canal)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+// EmailSender entrega un correo ya generado. El worker de notificaciones
+// reintenta cuando devuelve error.
+type EmailSender interface {
+	Enviar(ctx context.Context, notificacion *models.Notificacion) error
+}
+
+func NewEmailSender(tipo string) (EmailSender, error) {
+	smtpCfg := config.App.Notificaciones.SMTP
+
+	switch tipo {
+	case "smtp":
+		if smtpCfg.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST es obligatorio para el sender smtp")
+		}
+		return &SMTPSender{
+			Host:      smtpCfg.Host,
+			Port:      smtpCfg.Puerto,
+			Usuario:   smtpCfg.Usuario,
+			Password:  smtpCfg.Password,
+			Remitente: smtpCfg.Remitente,
+			Timeout:   time.Duration(smtpCfg.TimeoutSegundos) * time.Second,
+		}, nil
+	case "archivo":
+		return &FileSender{
+			Directorio: config.App.Notificaciones.Directorio,
+			Remitente:  smtpCfg.Remitente,
+		}, nil
+	default:
+		return nil, fmt.Errorf("sender de notificaciones desconocido: %s", tipo)
+	}
+}
+
+// SMTPSender envía los correos a través de un servidor SMTP.
+type SMTPSender struct {
+	Host      string
+	Port      string
+	Usuario   string
+	Password  string
+	Remitente string
+	Timeout   time.Duration
+}
+
+// Enviar entrega el correo respetando el contexto: la conversación con el
+// servidor se corta al vencer Timeout o al cancelarse ctx (por ejemplo, en el
+// apagado), así un servidor colgado no bloquea al worker.
+func (s *SMTPSender) Enviar(ctx context.Context, notificacion *models.Notificacion) error {
+	mensaje, err := construirMIME(s.Remitente, notificacion)
+	if err != nil {
+		return err
+	}
+
+	desde, err := mail.ParseAddress(s.Remitente)
+	if err != nil {
+		return fmt.Errorf("remitente inválido: %w", err)
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, s.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if limite, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(limite)
+	}
+	// Cancelar el contexto desbloquea cualquier lectura o escritura pendiente
+	detener := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer detener()
+
+	cliente, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return err
+	}
+	defer cliente.Close()
+
+	if ok, _ := cliente.Extension("STARTTLS"); ok {
+		if err := cliente.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if s.Usuario != "" {
+		if err := cliente.Auth(smtp.PlainAuth("", s.Usuario, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := cliente.Mail(desde.Address); err != nil {
+		return err
+	}
+	if err := cliente.Rcpt(notificacion.Destinatario); err != nil {
+		return err
+	}
+	w, err := cliente.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(mensaje); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return cliente.Quit()
+}
+
+// FileSender escribe cada correo como archivo .eml en un directorio local.
+// Pensado para desarrollo: los mensajes se pueden abrir con cualquier cliente de correo.
+type FileSender struct {
+	Directorio string
+	Remitente  string
+}
+
+func (f *FileSender) Enviar(ctx context.Context, notificacion *models.Notificacion) error {
+	mensaje, err := construirMIME(f.Remitente, notificacion)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Directorio, 0o755); err != nil {
+		return err
+	}
+
+	nombre := fmt.Sprintf("%s-%06d-%s.eml", time.Now().Format("20060102-150405"), notificacion.ID, notificacion.Plantilla)
+	return os.WriteFile(filepath.Join(f.Directorio, nombre), mensaje, 0o644)
+}
+
+// construirMIME arma un mensaje multipart/alternative con la versión en
+// texto plano y en HTML del correo.
+func construirMIME(remitente string, notificacion *models.Notificacion) ([]byte, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	limite := "gym-" + hex.EncodeToString(b)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", remitente)
+	fmt.Fprintf(&buf, "To: %s\r\n", notificacion.Destinatario)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notificacion.Asunto))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", limite)
+
+	partes := []struct {
+		tipo      string
+		contenido string
+	}{
+		{"text/plain", notificacion.CuerpoTexto},
+		{"text/html", notificacion.CuerpoHTML},
+	}
+	for _, parte := range partes {
+		fmt.Fprintf(&buf, "--%s\r\n", limite)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", parte.tipo)
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(parte.contenido)); err != nil {
+			return nil, err
+		}
+		qp.Close()
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", limite)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/models"
+)
+
+// servidorSMTP levanta un servidor local y atiende cada conexión con atender.
+func servidorSMTP(t *testing.T, atender func(net.Conn)) (host, puerto string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				atender(conn)
+			}()
+		}
+	}()
+	host, puerto, _ = net.SplitHostPort(ln.Addr().String())
+	return host, puerto
+}
+
+// smtpMinimo responde lo justo para aceptar un mensaje y lo entrega por recibido.
+func smtpMinimo(recibido chan<- string) func(net.Conn) {
+	return func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		var datos strings.Builder
+		enDatos := false
+		for {
+			linea, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if enDatos {
+				if linea == ".\r\n" {
+					enDatos = false
+					recibido <- datos.String()
+					conn.Write([]byte("250 OK\r\n"))
+					continue
+				}
+				datos.WriteString(linea)
+				continue
+			}
+			switch comando := strings.ToUpper(strings.Fields(linea)[0]); comando {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				conn.Write([]byte("250 OK\r\n"))
+			case "DATA":
+				enDatos = true
+				conn.Write([]byte("354 Continuar\r\n"))
+			case "QUIT":
+				conn.Write([]byte("221 Adiós\r\n"))
+				return
+			default:
+				conn.Write([]byte("502 No implementado\r\n"))
+			}
+		}
+	}
+}
+
+// smtpColgado acepta la conexión y nunca responde.
+func smtpColgado(conn net.Conn) {
+	conn.Read(make([]byte, 1))
+}
+
+func notificacionDePrueba() *models.Notificacion {
+	return &models.Notificacion{
+		ID:           1,
+		Plantilla:    "prueba",
+		Destinatario: "socio@gym.test",
+		Asunto:       "Inscripción confirmada",
+		CuerpoTexto:  "Hola",
+		CuerpoHTML:   "<p>Hola</p>",
+	}
+}
+
+func TestSMTPSenderEntrega(t *testing.T) {
+	recibido := make(chan string, 1)
+	host, puerto := servidorSMTP(t, smtpMinimo(recibido))
+	sender := &SMTPSender{Host: host, Port: puerto, Remitente: "Gym <no-responder@gym.test>", Timeout: 5 * time.Second}
+
+	if err := sender.Enviar(context.Background(), notificacionDePrueba()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case mensaje := <-recibido:
+		if !strings.Contains(mensaje, "To: socio@gym.test") {
+			t.Errorf("el mensaje recibido no tiene el destinatario:\n%s", mensaje)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("el servidor no recibió el mensaje")
+	}
+}
+
+func TestSMTPSenderNoQuedaColgado(t *testing.T) {
+	host, puerto := servidorSMTP(t, smtpColgado)
+
+	casos := []struct {
+		nombre  string
+		timeout time.Duration
+		cancela time.Duration
+	}{
+		{"vence el timeout", 100 * time.Millisecond, 0},
+		{"se cancela el contexto", 0, 100 * time.Millisecond},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			sender := &SMTPSender{Host: host, Port: puerto, Remitente: "no-responder@gym.test", Timeout: caso.timeout}
+			ctx := context.Background()
+			if caso.cancela > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				defer cancel()
+				time.AfterFunc(caso.cancela, cancel)
+			}
+
+			inicio := time.Now()
+			if err := sender.Enviar(ctx, notificacionDePrueba()); err == nil {
+				t.Fatal("se esperaba un error con el servidor colgado")
+			}
+			if demora := time.Since(inicio); demora > 2*time.Second {
+				t.Errorf("Enviar tardó %v en abandonar", demora)
+			}
+		})
+	}
+}
+
+func TestBackoffNotificacion(t *testing.T) {
+	casos := []struct {
+		intentos int
+		espera   time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, caso := range casos {
+		if got := backoffNotificacion(caso.intentos); got != caso.espera {
+			t.Errorf("backoffNotificacion(%d) = %v, se esperaba %v", caso.intentos, got, caso.espera)
+		}
+	}
+}
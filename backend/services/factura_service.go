@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFacturaNoEncontrada = apperrors.NoEncontrado("FACTURA_NO_ENCONTRADA", "Factura no encontrada")
+	ErrFacturaYaAnulada    = apperrors.Conflicto("FACTURA_ANULADA", "La factura ya está anulada")
+)
+
+// EmitirFactura numera y registra la factura de un pago confirmado. Debe
+// llamarse dentro de la misma transacción que aprueba el pago.
+func EmitirFactura(tx *gorm.DB, pago *models.Pago) (*models.Factura, error) {
+	var existente models.Factura
+	if err := tx.Where("pago_id = ?", pago.ID).First(&existente).Error; err == nil {
+		return &existente, nil
+	}
+
+	var ultima models.Factura
+	var secuencia uint = 1
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("secuencia DESC").First(&ultima).Error; err == nil {
+		secuencia = ultima.Secuencia + 1
+	}
+
+	factura := models.Factura{
+		Numero:       fmt.Sprintf("F-%08d", secuencia),
+		Secuencia:    secuencia,
+		PagoID:       pago.ID,
+		UsuarioID:    pago.UsuarioID,
+		Descripcion:  descripcionPago(tx, pago),
+		Monto:        pago.Monto,
+		Moneda:       pago.Moneda,
+		Estado:       models.FacturaEmitida,
+		FechaEmision: time.Now(),
+	}
+	if err := tx.Create(&factura).Error; err != nil {
+		return nil, err
+	}
+
+	return &factura, nil
+}
+
+func descripcionPago(tx *gorm.DB, pago *models.Pago) string {
+	if pago.Concepto == models.ConceptoSuscripcion {
+		return fmt.Sprintf("Suscripción plan %s", pago.Plan)
+	}
+
+	var actividad models.Actividad
+	if pago.ActividadID != nil && tx.First(&actividad, *pago.ActividadID).Error == nil {
+		return fmt.Sprintf("Clase suelta: %s (%s %s)", actividad.Titulo, actividad.Dia, actividad.Horario)
+	}
+	return "Clase suelta"
+}
+
+func AnularFactura(id uint, motivo string) (*models.Factura, error) {
+	var factura models.Factura
+	if err := config.DB.First(&factura, id).Error; err != nil {
+		return nil, ErrFacturaNoEncontrada
+	}
+
+	if factura.Estado == models.FacturaAnulada {
+		return nil, ErrFacturaYaAnulada
+	}
+
+	now := time.Now()
+	factura.Estado = models.FacturaAnulada
+	factura.AnuladaAt = &now
+	factura.MotivoAnulacion = motivo
+	if err := config.DB.Save(&factura).Error; err != nil {
+		return nil, err
+	}
+
+	return &factura, nil
+}
+
+// FormatearMonto convierte centavos al formato local, por ejemplo "$ 25.000,00".
+func FormatearMonto(centavos int64) string {
+	signo := ""
+	if centavos < 0 {
+		signo = "-"
+		centavos = -centavos
+	}
+
+	enteros := fmt.Sprintf("%d", centavos/100)
+	var partes []string
+	for len(enteros) > 3 {
+		partes = append([]string{enteros[len(enteros)-3:]}, partes...)
+		enteros = enteros[:len(enteros)-3]
+	}
+	partes = append([]string{enteros}, partes...)
+
+	return fmt.Sprintf("%s$ %s,%02d", signo, strings.Join(partes, "."), centavos%100)
+}
+
+// RenderFacturaPDF genera el comprobante en PDF (A4) de una factura con su usuario cargado.
+func RenderFacturaPDF(factura *models.Factura) ([]byte, error) {
+	emisor := config.App.Facturacion.RazonSocial
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(tr("Factura "+factura.Numero), false)
+	pdf.AddPage()
+
+	// Encabezado
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(120, 10, tr(emisor), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 10, tr("Factura "+factura.Numero), "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("Fecha de emisión: "+factura.FechaEmision.Format("02/01/2006")), "", 1, "R", false, 0, "")
+	pdf.Ln(8)
+
+	// Datos del socio
+	if factura.Usuario != nil {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 7, tr("Socio"), "B", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, tr(factura.Usuario.Nombre), "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, 6, tr(factura.Usuario.Email), "", 1, "L", false, 0, "")
+		pdf.Ln(6)
+	}
+
+	// Detalle
+	pdf.SetFillColor(230, 230, 230)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(140, 8, tr("Descripción"), "1", 0, "L", true, 0, "")
+	pdf.CellFormat(0, 8, tr("Importe"), "1", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(140, 8, tr(factura.Descripcion), "1", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, tr(FormatearMonto(factura.Monto)), "1", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(140, 9, tr("Total "+factura.Moneda), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 9, tr(FormatearMonto(factura.Monto)), "1", 1, "R", false, 0, "")
+
+	if factura.Estado == models.FacturaAnulada {
+		pdf.Ln(10)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.SetFont("Helvetica", "B", 28)
+		pdf.CellFormat(0, 14, tr("ANULADA"), "", 1, "C", false, 0, "")
+		if factura.MotivoAnulacion != "" {
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.CellFormat(0, 6, tr("Motivo: "+factura.MotivoAnulacion), "", 1, "C", false, 0, "")
+		}
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestFormatearMonto(t *testing.T) {
+	casos := []struct {
+		centavos int64
+		esperado string
+	}{
+		{0, "$ 0,00"},
+		{5, "$ 0,05"},
+		{99, "$ 0,99"},
+		{100, "$ 1,00"},
+		{150050, "$ 1.500,50"},
+		{2500000, "$ 25.000,00"},
+		{99999999, "$ 999.999,99"},
+		{24000000000, "$ 240.000.000,00"},
+		{-150050, "-$ 1.500,50"},
+	}
+	for _, caso := range casos {
+		if got := FormatearMonto(caso.centavos); got != caso.esperado {
+			t.Errorf("FormatearMonto(%d) = %q, se esperaba %q", caso.centavos, got, caso.esperado)
+		}
+	}
+}
+
+func TestEmitirFacturaNumeraCorrelativoEIdempotente(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+
+	var numeros []string
+	for i := 0; i < 3; i++ {
+		pago := &models.Pago{UsuarioID: socio.ID, Concepto: models.ConceptoSuscripcion, Plan: "mensual",
+			Monto: 2500000, Moneda: "ARS", Estado: models.PagoAprobado, Proveedor: "fake"}
+		crear(t, pago)
+		factura, err := EmitirFactura(config.DB, pago)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Reemitir para el mismo pago devuelve la factura existente
+		repetida, err := EmitirFactura(config.DB, pago)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repetida.ID != factura.ID {
+			t.Errorf("pago %d facturado dos veces: %d y %d", pago.ID, factura.ID, repetida.ID)
+		}
+		numeros = append(numeros, factura.Numero)
+	}
+
+	esperados := []string{"F-00000001", "F-00000002", "F-00000003"}
+	for i := range esperados {
+		if numeros[i] != esperados[i] {
+			t.Errorf("factura %d numerada %q, se esperaba %q", i, numeros[i], esperados[i])
+		}
+	}
+}
+
+func TestRenderFacturaPDF(t *testing.T) {
+	factura := &models.Factura{
+		Numero:      "F-00000001",
+		Descripcion: "Clase suelta: Funcional (Miércoles 19:00)",
+		Monto:       150000,
+		Moneda:      "ARS",
+		Estado:      models.FacturaAnulada,
+		Usuario:     &models.Usuario{Nombre: "Begoña Núñez", Email: "socia@gym.test"},
+	}
+	pdf, err := RenderFacturaPDF(factura)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Errorf("el resultado no es un PDF: %q", pdf[:min(len(pdf), 16)])
+	}
+}
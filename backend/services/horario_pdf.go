@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Máximo de combinaciones de filtros que se guardan; al llenarse se vacía.
+const maxHorariosPDF = 50
+
+type horarioPDF struct {
+	version   string
+	contenido []byte
+}
+
+var (
+	horariosPDF   = map[FiltroHorario]horarioPDF{}
+	horariosPDFMu sync.Mutex
+)
+
+// HorarioPDF devuelve el cronograma semanal en PDF y un ETag que cambia cada
+// vez que se crea, modifica o elimina una actividad. Mientras las
+// actividades no cambien se reutiliza el PDF ya generado.
+func HorarioPDF(filtro FiltroHorario) ([]byte, string, error) {
+	version, err := versionActividades()
+	if err != nil {
+		return nil, "", err
+	}
+	suma := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", version, filtro.Categoria, filtro.Profesor, filtro.Sala)))
+	etag := `"` + hex.EncodeToString(suma[:8]) + `"`
+
+	horariosPDFMu.Lock()
+	cacheado, ok := horariosPDF[filtro]
+	horariosPDFMu.Unlock()
+	if ok && cacheado.version == version {
+		return cacheado.contenido, etag, nil
+	}
+
+	horario, err := GetHorarioSemanal(filtro, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	contenido, err := renderHorarioPDF(horario, filtro)
+	if err != nil {
+		return nil, "", err
+	}
+
+	horariosPDFMu.Lock()
+	if len(horariosPDF) >= maxHorariosPDF {
+		horariosPDF = map[FiltroHorario]horarioPDF{}
+	}
+	horariosPDF[filtro] = horarioPDF{version: version, contenido: contenido}
+	horariosPDFMu.Unlock()
+
+	return contenido, etag, nil
+}
+
+// versionActividades resume el estado de la tabla de actividades. Incluye
+// las eliminadas para que una baja también invalide el PDF. Las fechas se leen
+// como texto: sólo sirven para comparar versiones y así no dependen del driver.
+func versionActividades() (string, error) {
+	var estado struct {
+		Total      int64
+		Modificada sql.NullString
+		Eliminada  sql.NullString
+	}
+	if err := config.DB.Unscoped().Model(&models.Actividad{}).
+		Select("COUNT(*) AS total, MAX(updated_at) AS modificada, MAX(deleted_at) AS eliminada").
+		Scan(&estado).Error; err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d|%s|%s", estado.Total, estado.Modificada.String, estado.Eliminada.String), nil
+}
+
+// renderHorarioPDF dibuja la grilla en A4 apaisado: una columna por día y una
+// fila por cada hora de inicio que tenga alguna clase en la semana.
+func renderHorarioPDF(horario *HorarioSemanal, filtro FiltroHorario) ([]byte, error) {
+	const (
+		margen       = 10.0
+		anchoHora    = 17.0
+		altoLinea    = 3.8
+		altoEncabeza = 7.0
+	)
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(tr("Cronograma semanal"), false)
+	pdf.SetMargins(margen, margen, margen)
+	pdf.SetAutoPageBreak(false, margen)
+
+	ancho, alto := pdf.GetPageSize()
+	anchoDia := (ancho - 2*margen - anchoHora) / float64(len(horario.Dias))
+
+	// Horas de inicio de todas las franjas de la semana, ordenadas
+	var horas []string
+	vistas := map[string]bool{}
+	for _, dia := range horario.Dias {
+		for _, franja := range dia.Franjas {
+			if !vistas[franja.Inicio] {
+				vistas[franja.Inicio] = true
+				horas = append(horas, franja.Inicio)
+			}
+		}
+	}
+	sort.Strings(horas)
+
+	// Filtros aplicados, para el subtítulo
+	var filtros []string
+	if filtro.Categoria != "" {
+		filtros = append(filtros, "Categoría: "+filtro.Categoria)
+	}
+	if filtro.Profesor != "" {
+		filtros = append(filtros, "Profesor: "+filtro.Profesor)
+	}
+	if filtro.Sala != "" {
+		filtros = append(filtros, "Sala: "+filtro.Sala)
+	}
+
+	encabezado := func() {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(150, 9, tr(config.App.Facturacion.RazonSocial), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(0, 9, tr("Cronograma semanal"), "", 1, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(150, 5, tr(strings.Join(filtros, " · ")), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, tr("Actualizado: "+time.Now().Format("02/01/2006 15:04")), "", 1, "R", false, 0, "")
+		pdf.Ln(3)
+
+		pdf.SetFillColor(40, 40, 40)
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(anchoHora, altoEncabeza, tr("Hora"), "1", 0, "C", true, 0, "")
+		for _, dia := range horario.Dias {
+			pdf.CellFormat(anchoDia, altoEncabeza, tr(dia.Dia), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	// lineas arma el texto ya convertido de una celda: por cada clase, el
+	// título en negrita y debajo el horario con la sala y el profesor.
+	type linea struct {
+		texto   string
+		negrita bool
+	}
+	lineas := func(clases []ClaseHorario) []linea {
+		var resultado []linea
+		for i, clase := range clases {
+			if i > 0 {
+				resultado = append(resultado, linea{})
+			}
+			pdf.SetFont("Helvetica", "B", 8)
+			for _, parte := range pdf.SplitLines([]byte(tr(clase.Titulo)), anchoDia-2) {
+				resultado = append(resultado, linea{texto: string(parte), negrita: true})
+			}
+			pdf.SetFont("Helvetica", "", 7)
+			cuando := clase.Horario + " - " + clase.HoraFin
+			if clase.Sala != "" {
+				cuando += " · Sala " + clase.Sala
+			}
+			detalles := []string{cuando, clase.Profesor}
+			for _, detalle := range detalles {
+				for _, parte := range pdf.SplitLines([]byte(tr(detalle)), anchoDia-2) {
+					resultado = append(resultado, linea{texto: string(parte)})
+				}
+			}
+		}
+		return resultado
+	}
+
+	encabezado()
+	for _, hora := range horas {
+		celdas := make([][]linea, len(horario.Dias))
+		maxLineas := 1
+		for i, dia := range horario.Dias {
+			for _, franja := range dia.Franjas {
+				if franja.Inicio == hora {
+					celdas[i] = lineas(franja.Clases)
+				}
+			}
+			if len(celdas[i]) > maxLineas {
+				maxLineas = len(celdas[i])
+			}
+		}
+		altoFila := float64(maxLineas)*altoLinea + 2
+
+		if pdf.GetY()+altoFila > alto-margen {
+			encabezado()
+		}
+
+		x, y := margen, pdf.GetY()
+		pdf.SetFillColor(230, 230, 230)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetXY(x, y)
+		pdf.CellFormat(anchoHora, altoFila, hora, "1", 0, "C", true, 0, "")
+		for i, celda := range celdas {
+			cx := x + anchoHora + float64(i)*anchoDia
+			pdf.Rect(cx, y, anchoDia, altoFila, "D")
+			for j, l := range celda {
+				if l.negrita {
+					pdf.SetFont("Helvetica", "B", 8)
+				} else {
+					pdf.SetFont("Helvetica", "", 7)
+				}
+				pdf.SetXY(cx+1, y+1+float64(j)*altoLinea)
+				pdf.CellFormat(anchoDia-2, altoLinea, l.texto, "", 0, "L", false, 0, "")
+			}
+		}
+		pdf.SetXY(x, y+altoFila)
+	}
+
+	if len(horas) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.Ln(4)
+		pdf.CellFormat(0, 6, tr("No hay clases con horario fijo."), "", 1, "C", false, 0, "")
+	}
+
+	// Actividades sin día u hora fijos, debajo de la grilla
+	if len(horario.HorarioLibre) > 0 {
+		if pdf.GetY()+20 > alto-margen {
+			encabezado()
+		}
+		pdf.Ln(5)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 6, tr("Horario libre"), "B", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		for _, clase := range horario.HorarioLibre {
+			if pdf.GetY()+5 > alto-margen {
+				encabezado()
+			}
+			texto := clase.Titulo + " - " + clase.Profesor
+			if clase.Sala != "" {
+				texto += " - Sala " + clase.Sala
+			}
+			pdf.CellFormat(0, 5, tr(texto), "", 1, "L", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
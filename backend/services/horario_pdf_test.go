@@ -0,0 +1,50 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"proyecto-gym-backend/config"
+)
+
+func TestHorarioPDFCacheYETag(t *testing.T) {
+	prepararDB(t)
+	yoga := nuevaActividad("Yoga", "Lunes", "18:00", 10)
+	crear(t, yoga, nuevaActividad("Boxeo", "Martes", "19:00", 10))
+
+	pdf, etag, err := HorarioPDF(FiltroHorario{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatal("el resultado no es un PDF")
+	}
+
+	_, repetido, err := HorarioPDF(FiltroHorario{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repetido != etag {
+		t.Errorf("sin cambios el ETag pasó de %s a %s", etag, repetido)
+	}
+
+	_, filtrado, err := HorarioPDF(FiltroHorario{Categoria: "General"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtrado == etag {
+		t.Error("otro filtro debería tener otro ETag")
+	}
+
+	// Eliminar una actividad invalida el PDF
+	if err := config.DB.Delete(yoga).Error; err != nil {
+		t.Fatal(err)
+	}
+	_, trasBaja, err := HorarioPDF(FiltroHorario{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trasBaja == etag {
+		t.Error("el ETag no cambió tras eliminar una actividad")
+	}
+}
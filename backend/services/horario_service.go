@@ -0,0 +1,139 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+// DiasHorario son los días de la grilla semanal, en el orden en que se muestran.
+var DiasHorario = []string{"Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado", "Domingo"}
+
+type FiltroHorario struct {
+	Categoria string
+	Profesor  string
+	Sala      string
+}
+
+// ClaseHorario es una actividad ubicada en la grilla, con su hora de fin.
+// Inscrito sólo se informa cuando la petición trae un usuario.
+type ClaseHorario struct {
+	models.Actividad
+	HoraFin  string `json:"hora_fin,omitempty"`
+	Inscrito *bool  `json:"inscrito,omitempty"`
+}
+
+// FranjaHorario agrupa las clases de un día que empiezan a la misma hora.
+type FranjaHorario struct {
+	Inicio string         `json:"inicio"`
+	Clases []ClaseHorario `json:"clases"`
+}
+
+type DiaHorario struct {
+	Dia     string          `json:"dia"`
+	Franjas []FranjaHorario `json:"franjas"`
+}
+
+// HorarioSemanal es el cronograma armado como grilla: los siete días, aunque
+// no tengan clases, y aparte las actividades sin día u hora fijos.
+type HorarioSemanal struct {
+	Dias         []DiaHorario   `json:"dias"`
+	HorarioLibre []ClaseHorario `json:"horario_libre"`
+}
+
+// GetHorarioSemanal arma la grilla semanal con el cupo disponible de cada
+// clase. Con usuarioID distinto de cero marca en qué clases está inscrito.
+func GetHorarioSemanal(filtro FiltroHorario, usuarioID uint) (*HorarioSemanal, error) {
+	query := config.DB.Model(&models.Actividad{})
+	if filtro.Categoria != "" {
+		query = query.Where("categoria = ?", filtro.Categoria)
+	}
+	if filtro.Profesor != "" {
+		query = query.Where("profesor = ?", filtro.Profesor)
+	}
+	if filtro.Sala != "" {
+		query = query.Where("sala = ?", filtro.Sala)
+	}
+
+	var actividades []models.Actividad
+	if err := query.Order("horario, titulo").Find(&actividades).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(actividades))
+	for i := range actividades {
+		ids[i] = actividades[i].ID
+	}
+
+	inscritos := map[uint]int{}
+	inscripto := map[uint]bool{}
+	if len(ids) > 0 {
+		var conteos []struct {
+			ActividadID uint
+			Total       int
+		}
+		if err := config.DB.Model(&models.Inscripcion{}).Select("actividad_id, COUNT(*) AS total").
+			Where("actividad_id IN ?", ids).Group("actividad_id").Scan(&conteos).Error; err != nil {
+			return nil, err
+		}
+		for _, conteo := range conteos {
+			inscritos[conteo.ActividadID] = conteo.Total
+		}
+
+		if usuarioID != 0 {
+			var propias []uint
+			if err := config.DB.Model(&models.Inscripcion{}).Where("usuario_id = ? AND actividad_id IN ?", usuarioID, ids).
+				Pluck("actividad_id", &propias).Error; err != nil {
+				return nil, err
+			}
+			for _, id := range propias {
+				inscripto[id] = true
+			}
+		}
+	}
+
+	horario := &HorarioSemanal{Dias: make([]DiaHorario, len(DiasHorario)), HorarioLibre: []ClaseHorario{}}
+	posicion := map[string]int{}
+	franjas := map[string]int{} // "día hora" -> índice de la franja en su día
+	for i, dia := range DiasHorario {
+		horario.Dias[i] = DiaHorario{Dia: dia, Franjas: []FranjaHorario{}}
+		posicion[dia] = i
+	}
+
+	for _, actividad := range actividades {
+		actividad.CupoDisponible = actividad.CupoMaximo - inscritos[actividad.ID]
+		clase := ClaseHorario{Actividad: actividad}
+		if usuarioID != 0 {
+			estaInscrito := inscripto[actividad.ID]
+			clase.Inscrito = &estaInscrito
+		}
+
+		i, conDia := posicion[actividad.Dia]
+		hora, minuto, conHora := HoraInicio(&actividad)
+		if !conDia || !conHora {
+			horario.HorarioLibre = append(horario.HorarioLibre, clase)
+			continue
+		}
+		comienzo := time.Date(2000, 1, 1, hora, minuto, 0, 0, time.UTC)
+		clase.HoraFin = comienzo.Add(time.Duration(actividad.DuracionMinutos) * time.Minute).Format("15:04")
+
+		dia := &horario.Dias[i]
+		inicio := comienzo.Format("15:04")
+		clave := actividad.Dia + " " + inicio
+		if j, ok := franjas[clave]; ok {
+			dia.Franjas[j].Clases = append(dia.Franjas[j].Clases, clase)
+		} else {
+			franjas[clave] = len(dia.Franjas)
+			dia.Franjas = append(dia.Franjas, FranjaHorario{Inicio: inicio, Clases: []ClaseHorario{clase}})
+		}
+	}
+
+	// La consulta ordena el horario como texto y "9:00" quedaría después de "18:00"
+	for i := range horario.Dias {
+		f := horario.Dias[i].Franjas
+		sort.SliceStable(f, func(a, b int) bool { return f[a].Inicio < f[b].Inicio })
+	}
+	return horario, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"proyecto-gym-backend/models"
+)
+
+// resumenHorario reduce la grilla a "día hora: títulos" para comparar el orden.
+func resumenHorario(horario *HorarioSemanal) (dias []string, franjas map[string][]string, libres []string) {
+	franjas = map[string][]string{}
+	for _, dia := range horario.Dias {
+		dias = append(dias, dia.Dia)
+		for _, franja := range dia.Franjas {
+			clave := dia.Dia + " " + franja.Inicio
+			for _, clase := range franja.Clases {
+				franjas[clave] = append(franjas[clave], clase.Titulo)
+			}
+		}
+	}
+	for _, clase := range horario.HorarioLibre {
+		libres = append(libres, clase.Titulo)
+	}
+	return dias, franjas, libres
+}
+
+func TestGetHorarioSemanalOrden(t *testing.T) {
+	prepararDB(t)
+	crear(t,
+		nuevaActividad("Yoga", "Lunes", "18:00", 10),
+		nuevaActividad("Boxeo", "Lunes", "9:00", 10),
+		nuevaActividad("Aeróbica", "Lunes", "09:00", 10),
+		nuevaActividad("Funcional", "Lunes", "18:00", 10),
+		nuevaActividad("Stretching", "Lunes", "10:30", 10),
+		nuevaActividad("Caminata", "Domingo", "07:30", 10),
+		nuevaActividad("Musculación", "Lunes", "Horario Libre", 10),
+		nuevaActividad("Pileta", "Todos", "08:00", 10),
+	)
+
+	horario, err := GetHorarioSemanal(FiltroHorario{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dias, franjas, libres := resumenHorario(horario)
+
+	if !reflect.DeepEqual(dias, DiasHorario) {
+		t.Errorf("días = %v, se esperaba %v", dias, DiasHorario)
+	}
+	esperadas := map[string][]string{
+		"Lunes 09:00":   {"Aeróbica", "Boxeo"},
+		"Lunes 10:30":   {"Stretching"},
+		"Lunes 18:00":   {"Funcional", "Yoga"},
+		"Domingo 07:30": {"Caminata"},
+	}
+	if !reflect.DeepEqual(franjas, esperadas) {
+		t.Errorf("franjas = %v, se esperaba %v", franjas, esperadas)
+	}
+	var inicios []string
+	for _, franja := range horario.Dias[0].Franjas {
+		inicios = append(inicios, franja.Inicio)
+	}
+	if !reflect.DeepEqual(inicios, []string{"09:00", "10:30", "18:00"}) {
+		t.Errorf("franjas del lunes en orden %v", inicios)
+	}
+	if !reflect.DeepEqual(libres, []string{"Pileta", "Musculación"}) {
+		t.Errorf("horario libre = %v", libres)
+	}
+	if fin := horario.Dias[0].Franjas[2].Clases[0].HoraFin; fin != "19:00" {
+		t.Errorf("HoraFin = %q, se esperaba 19:00", fin)
+	}
+}
+
+func TestGetHorarioSemanalInscritoYCupo(t *testing.T) {
+	prepararDB(t)
+	yoga := nuevaActividad("Yoga", "Martes", "18:00", 2)
+	yoga.Sala = "Sala A"
+	boxeo := nuevaActividad("Boxeo", "Martes", "19:00", 5)
+	boxeo.Sala = "Sala B"
+	socio := nuevoUsuario("socio@gym.test")
+	otro := nuevoUsuario("otro@gym.test")
+	crear(t, yoga, boxeo, socio, otro)
+	crear(t,
+		&models.Inscripcion{UsuarioID: socio.ID, ActividadID: yoga.ID},
+		&models.Inscripcion{UsuarioID: otro.ID, ActividadID: yoga.ID},
+		&models.Inscripcion{UsuarioID: otro.ID, ActividadID: boxeo.ID},
+	)
+
+	casos := []struct {
+		nombre    string
+		filtro    FiltroHorario
+		usuarioID uint
+		esperadas map[string]string // título -> "cupo/inscrito"
+	}{
+		{"anónimo", FiltroHorario{}, 0, map[string]string{"Yoga": "0/-", "Boxeo": "4/-"}},
+		{"con usuario", FiltroHorario{}, socio.ID, map[string]string{"Yoga": "0/true", "Boxeo": "4/false"}},
+		{"filtrado por sala", FiltroHorario{Sala: "Sala B"}, socio.ID, map[string]string{"Boxeo": "4/false"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			horario, err := GetHorarioSemanal(caso.filtro, caso.usuarioID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obtenidas := map[string]string{}
+			for _, franja := range horario.Dias[1].Franjas {
+				for _, clase := range franja.Clases {
+					inscrito := "-"
+					if clase.Inscrito != nil {
+						inscrito = fmt.Sprint(*clase.Inscrito)
+					}
+					obtenidas[clase.Titulo] = fmt.Sprintf("%d/%s", clase.CupoDisponible, inscrito)
+				}
+			}
+			if !reflect.DeepEqual(obtenidas, caso.esperadas) {
+				t.Errorf("clases = %v, se esperaba %v", obtenidas, caso.esperadas)
+			}
+		})
+	}
+}
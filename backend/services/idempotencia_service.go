@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LargoMaximoClaveIdempotencia es el largo máximo del header Idempotency-Key.
+const LargoMaximoClaveIdempotencia = 255
+
+// Tiempo tras el cual una petición que quedó en curso (por ejemplo, porque
+// el proceso se reinició) se da por abandonada y la clave se puede reusar.
+const abandonoIdempotencia = 5 * time.Minute
+
+var (
+	ErrClaveIdempotenciaInvalida = apperrors.Invalido("IDEMPOTENCY_KEY_INVALIDA", "El header Idempotency-Key debe tener entre 1 y 255 caracteres")
+	ErrIdempotenciaEnCurso       = apperrors.Conflicto("IDEMPOTENCIA_EN_CURSO", "Ya se está procesando una petición con esta Idempotency-Key")
+	ErrIdempotenciaReutilizada   = apperrors.New(http.StatusUnprocessableEntity, "IDEMPOTENCIA_REUTILIZADA", "La Idempotency-Key ya se usó con otros datos")
+)
+
+// ReservarClaveIdempotencia registra la clave antes de procesar la petición.
+// Si la clave ya tiene una respuesta guardada la devuelve con nueva = false
+// para repetirla. Una clave con otro cuerpo o todavía en curso es un error.
+func ReservarClaveIdempotencia(usuarioID uint, ruta, clave, hash string) (registro *models.ClaveIdempotencia, nueva bool, err error) {
+	ahora := time.Now()
+	for intento := 0; intento < 2; intento++ {
+		registro = &models.ClaveIdempotencia{
+			UsuarioID:    usuarioID,
+			Ruta:         ruta,
+			Clave:        clave,
+			HashPeticion: hash,
+			ExpiraAt:     ahora.Add(time.Duration(config.App.Idempotencia.TTLHoras) * time.Hour),
+		}
+		resultado := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(registro)
+		if resultado.Error != nil {
+			return nil, false, resultado.Error
+		}
+		if resultado.RowsAffected == 1 {
+			return registro, true, nil
+		}
+
+		var existente models.ClaveIdempotencia
+		if err := config.DB.Where("usuario_id = ? AND ruta = ? AND clave = ?", usuarioID, ruta, clave).
+			First(&existente).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // se liberó entre el insert y la consulta
+			}
+			return nil, false, err
+		}
+
+		vencida := existente.ExpiraAt.Before(ahora)
+		abandonada := existente.Status == 0 && existente.CreatedAt.Before(ahora.Add(-abandonoIdempotencia))
+		if vencida || abandonada {
+			if err := config.DB.Delete(&existente).Error; err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		if existente.HashPeticion != hash {
+			return nil, false, ErrIdempotenciaReutilizada
+		}
+		if existente.Status == 0 {
+			return nil, false, ErrIdempotenciaEnCurso
+		}
+		return &existente, false, nil
+	}
+	return nil, false, ErrIdempotenciaEnCurso
+}
+
+// GuardarRespuestaIdempotencia completa la clave con la respuesta enviada.
+func GuardarRespuestaIdempotencia(id uint, status int, tipoContenido string, cuerpo []byte) error {
+	return config.DB.Model(&models.ClaveIdempotencia{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":         status,
+		"tipo_contenido": tipoContenido,
+		"cuerpo":         cuerpo,
+	}).Error
+}
+
+// LiberarClaveIdempotencia borra una clave cuya petición falló, para que el
+// cliente pueda reintentar con la misma clave.
+func LiberarClaveIdempotencia(id uint) error {
+	return config.DB.Delete(&models.ClaveIdempotencia{}, id).Error
+}
+
+// IniciarLimpiezaIdempotencia borra cada hora las claves vencidas hasta que
+// se cancele el contexto.
+func IniciarLimpiezaIdempotencia(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			resultado := config.DB.Where("expira_at < ?", time.Now()).Delete(&models.ClaveIdempotencia{})
+			if resultado.Error != nil {
+				slog.Error("error limpiando claves de idempotencia", "error", resultado.Error)
+			} else if resultado.RowsAffected > 0 {
+				slog.Debug("claves de idempotencia vencidas eliminadas", "cantidad", resultado.RowsAffected)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestReservarClaveIdempotencia(t *testing.T) {
+	const ruta = "POST /inscripciones"
+
+	casos := []struct {
+		nombre string
+		previa func(t *testing.T) // estado de la clave "k1" del usuario 1 antes de reintentar
+		// reintento
+		usuarioID uint
+		ruta      string
+		hash      string
+		nueva     bool
+		err       error
+	}{
+		{"clave sin usar", func(t *testing.T) {}, 1, ruta, "h1", true, nil},
+		{"respuesta guardada se repite", guardada, 1, ruta, "h1", false, nil},
+		{"misma clave con otros datos", guardada, 1, ruta, "h2", false, ErrIdempotenciaReutilizada},
+		{"petición todavía en curso", enCurso, 1, ruta, "h1", false, ErrIdempotenciaEnCurso},
+		{"otro usuario con la misma clave", guardada, 2, ruta, "h2", true, nil},
+		{"otra ruta con la misma clave", guardada, 1, "POST /pagos", "h2", true, nil},
+		{"clave liberada tras un error", liberada, 1, ruta, "h2", true, nil},
+		{"clave vencida", vencida, 1, ruta, "h2", true, nil},
+		{"petición abandonada", abandonada, 1, ruta, "h1", true, nil},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			caso.previa(t)
+
+			registro, nueva, err := ReservarClaveIdempotencia(caso.usuarioID, caso.ruta, "k1", caso.hash)
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("err = %v, se esperaba %v", err, caso.err)
+			}
+			if err != nil {
+				return
+			}
+			if nueva != caso.nueva {
+				t.Errorf("nueva = %v, se esperaba %v", nueva, caso.nueva)
+			}
+			if !nueva && (registro.Status != 201 || string(registro.Cuerpo) != `{"id":1}`) {
+				t.Errorf("respuesta repetida = %d %s", registro.Status, registro.Cuerpo)
+			}
+		})
+	}
+}
+
+// reservar deja la clave "k1" del usuario 1 en curso y devuelve su registro.
+func reservar(t *testing.T) *models.ClaveIdempotencia {
+	t.Helper()
+	registro, nueva, err := ReservarClaveIdempotencia(1, "POST /inscripciones", "k1", "h1")
+	if err != nil || !nueva {
+		t.Fatalf("no se pudo reservar la clave: nueva = %v, err = %v", nueva, err)
+	}
+	return registro
+}
+
+func enCurso(t *testing.T) {
+	reservar(t)
+}
+
+func guardada(t *testing.T) {
+	registro := reservar(t)
+	if err := GuardarRespuestaIdempotencia(registro.ID, 201, "application/json", []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func liberada(t *testing.T) {
+	registro := reservar(t)
+	if err := LiberarClaveIdempotencia(registro.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func vencida(t *testing.T) {
+	guardada(t)
+	config.DB.Model(&models.ClaveIdempotencia{}).Where("clave = ?", "k1").
+		Update("expira_at", time.Now().Add(-time.Minute))
+}
+
+func abandonada(t *testing.T) {
+	reservar(t)
+	config.DB.Model(&models.ClaveIdempotencia{}).Where("clave = ?", "k1").
+		Update("created_at", time.Now().Add(-abandonoIdempotencia-time.Minute))
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// Formatos de archivo para importar y exportar actividades
+const (
+	FormatoCSV  = "csv"
+	FormatoXLSX = "xlsx"
+)
+
+var (
+	ErrFormatoNoSoportado = apperrors.Invalido("FORMATO_NO_SOPORTADO", "Formato de archivo no soportado")
+	ErrArchivoInvalido    = apperrors.Invalido("ARCHIVO_INVALIDO", "No se pudo leer el archivo")
+	ErrColumnasFaltantes  = apperrors.Invalido("COLUMNAS_FALTANTES", "Faltan columnas obligatorias: {columnas}")
+	ErrClaveOtraActividad = apperrors.Conflicto("CLAVE_OTRA_ACTIVIDAD", "La clave externa pertenece a otra actividad")
+)
+
+// ColumnasActividad son las columnas del archivo de actividades, en el orden
+// en que se exportan. Al importar el orden no importa.
+var ColumnasActividad = []string{
+	"id", "clave_externa", "titulo", "categoria", "descripcion", "dia", "horario",
+	"duracion_minutos", "cupo_maximo", "profesor", "sala", "foto_url",
+}
+
+var columnasObligatorias = []string{"titulo", "categoria", "dia", "horario", "duracion_minutos", "cupo_maximo", "profesor"}
+
+// FilaActividad es una fila del archivo ya convertida, lista para validar.
+type FilaActividad struct {
+	ID              uint   `json:"id"`
+	ClaveExterna    string `json:"clave_externa" binding:"max=64"`
+	Titulo          string `json:"titulo" binding:"required"`
+	Categoria       string `json:"categoria" binding:"required"`
+	Descripcion     string `json:"descripcion"`
+	Dia             string `json:"dia" binding:"required,oneof=Lunes Martes Miércoles Jueves Viernes Sábado Domingo 'Horario Libre'"`
+	Horario         string `json:"horario" binding:"required"`
+	DuracionMinutos int    `json:"duracion_minutos" binding:"min=1"`
+	CupoMaximo      int    `json:"cupo_maximo" binding:"min=1"`
+	Profesor        string `json:"profesor" binding:"required"`
+	Sala            string `json:"sala"`
+	FotoURL         string `json:"foto_url"`
+}
+
+type ErrorFila struct {
+	Fila   int                    `json:"fila"` // número de fila en el archivo, contando el encabezado
+	Campos []apperrors.CampoError `json:"campos"`
+}
+
+// ResultadoImportacion resume qué hizo (o haría, en una simulación) la importación.
+type ResultadoImportacion struct {
+	Simulacion   bool        `json:"simulacion"`
+	Aplicada     bool        `json:"aplicada"`
+	Filas        int         `json:"filas"`
+	Creadas      int         `json:"creadas"`
+	Actualizadas int         `json:"actualizadas"`
+	SinCambios   int         `json:"sin_cambios"`
+	Errores      []ErrorFila `json:"errores"`
+}
+
+// FormatoArchivo deduce el formato por la extensión del nombre de archivo.
+func FormatoArchivo(nombre string) (string, error) {
+	switch {
+	case strings.HasSuffix(strings.ToLower(nombre), ".csv"):
+		return FormatoCSV, nil
+	case strings.HasSuffix(strings.ToLower(nombre), ".xlsx"):
+		return FormatoXLSX, nil
+	default:
+		return "", ErrFormatoNoSoportado
+	}
+}
+
+// ImportarActividades crea o actualiza actividades a partir de un archivo.
+// Cada fila se busca por clave_externa y, si la clave no está guardada, por
+// id; si no existe se crea. Si alguna fila tiene errores no se aplica ningún
+// cambio, y con simular sólo se informa lo que se haría.
+func ImportarActividades(r io.Reader, formato string, simular bool) (*ResultadoImportacion, error) {
+	registros, err := leerRegistros(r, formato)
+	if err != nil {
+		return nil, err
+	}
+	if len(registros) == 0 {
+		return nil, ErrColumnasFaltantes.ConDetalle("columnas", strings.Join(columnasObligatorias, ", "))
+	}
+
+	indices := map[string]int{}
+	for i, nombre := range registros[0] {
+		indices[strings.ToLower(strings.TrimSpace(nombre))] = i
+	}
+	var faltantes []string
+	for _, columna := range columnasObligatorias {
+		if _, ok := indices[columna]; !ok {
+			faltantes = append(faltantes, columna)
+		}
+	}
+	if len(faltantes) > 0 {
+		return nil, ErrColumnasFaltantes.ConDetalle("columnas", strings.Join(faltantes, ", "))
+	}
+
+	resultado := &ResultadoImportacion{Simulacion: simular, Errores: []ErrorFila{}}
+	filas := make([]FilaActividad, 0, len(registros)-1)
+	numeros := make([]int, 0, len(registros)-1)
+	claves := map[string]bool{}
+	for n, registro := range registros[1:] {
+		if strings.TrimSpace(strings.Join(registro, "")) == "" {
+			continue
+		}
+		valor := func(columna string) string {
+			if i, ok := indices[columna]; ok && i < len(registro) {
+				return strings.TrimSpace(registro[i])
+			}
+			return ""
+		}
+
+		numero := n + 2 // el encabezado es la fila 1
+		fila, campos := convertirFila(valor)
+		if fila.ClaveExterna != "" {
+			if claves[fila.ClaveExterna] {
+				campos = append(campos, apperrors.NuevoCampoError("clave_externa", "unique", "CLAVE_REPETIDA"))
+			}
+			claves[fila.ClaveExterna] = true
+		}
+		if len(campos) > 0 {
+			resultado.Errores = append(resultado.Errores, ErrorFila{Fila: numero, Campos: campos})
+			continue
+		}
+		filas = append(filas, fila)
+		numeros = append(numeros, numero)
+	}
+	resultado.Filas = len(filas) + len(resultado.Errores)
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for i, fila := range filas {
+			if err := importarFila(tx, fila, resultado); err != nil {
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) {
+					return err
+				}
+				campo := "id"
+				if errors.Is(err, ErrClaveOtraActividad) {
+					campo = "clave_externa"
+				}
+				resultado.Errores = append(resultado.Errores, ErrorFila{
+					Fila:   numeros[i],
+					Campos: []apperrors.CampoError{apperrors.NuevoCampoError(campo, "existe", appErr.Codigo)},
+				})
+			}
+		}
+
+		// Una simulación o un archivo con errores no deja cambios
+		if simular || len(resultado.Errores) > 0 {
+			return errSinAplicar
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSinAplicar) {
+		return nil, err
+	}
+	sort.Slice(resultado.Errores, func(i, j int) bool { return resultado.Errores[i].Fila < resultado.Errores[j].Fila })
+
+	resultado.Aplicada = err == nil
+	return resultado, nil
+}
+
+// errSinAplicar revierte la transacción de una importación que no se aplica.
+var errSinAplicar = errors.New("importación sin aplicar")
+
+func convertirFila(valor func(columna string) string) (FilaActividad, []apperrors.CampoError) {
+	var campos []apperrors.CampoError
+	entero := func(columna string) int {
+		texto := valor(columna)
+		if texto == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(texto)
+		if err != nil {
+			campos = append(campos, apperrors.NuevoCampoError(columna, "tipo", apperrors.ClaveTipo(reflect.TypeOf(n))))
+		}
+		return n
+	}
+
+	fila := FilaActividad{
+		ID:              uint(entero("id")),
+		ClaveExterna:    valor("clave_externa"),
+		Titulo:          valor("titulo"),
+		Categoria:       valor("categoria"),
+		Descripcion:     valor("descripcion"),
+		Dia:             valor("dia"),
+		Horario:         valor("horario"),
+		DuracionMinutos: entero("duracion_minutos"),
+		CupoMaximo:      entero("cupo_maximo"),
+		Profesor:        valor("profesor"),
+		Sala:            valor("sala"),
+		FotoURL:         valor("foto_url"),
+	}
+
+	// Las columnas que no se pudieron convertir ya tienen su error
+	conError := map[string]bool{}
+	for _, campo := range campos {
+		conError[campo.Campo] = true
+	}
+	if err := binding.Validator.ValidateStruct(&fila); err != nil {
+		for _, campo := range apperrors.Desde(err).Campos {
+			if !conError[campo.Campo] {
+				campos = append(campos, campo)
+			}
+		}
+	}
+	if fila.Horario != "" && fila.Horario != "Horario Libre" {
+		if _, err := time.Parse("15:04", fila.Horario); err != nil {
+			campos = append(campos, apperrors.NuevoCampoError("horario", "horario", "HORARIO_INVALIDO"))
+		}
+	}
+	return fila, campos
+}
+
+// importarFila crea o actualiza la actividad de una fila dentro de la
+// transacción. Una fila con id y una clave todavía no guardada actualiza esa
+// actividad y le asigna la clave, como al agregar claves a un archivo exportado.
+func importarFila(tx *gorm.DB, fila FilaActividad, resultado *ResultadoImportacion) error {
+	var actividad models.Actividad
+	existe := false
+	if fila.ClaveExterna != "" {
+		err := tx.Unscoped().Where("clave_externa = ?", fila.ClaveExterna).First(&actividad).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		existe = err == nil
+		if existe && fila.ID != 0 && actividad.ID != fila.ID {
+			return ErrClaveOtraActividad
+		}
+	}
+	if !existe && fila.ID != 0 {
+		if err := tx.First(&actividad, fila.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrActividadNoEncontrada
+			}
+			return err
+		}
+		existe = true
+	}
+
+	anterior := actividad
+	actividad.Titulo = fila.Titulo
+	actividad.Categoria = fila.Categoria
+	actividad.Descripcion = fila.Descripcion
+	actividad.Dia = fila.Dia
+	actividad.Horario = fila.Horario
+	actividad.DuracionMinutos = fila.DuracionMinutos
+	actividad.CupoMaximo = fila.CupoMaximo
+	actividad.Profesor = fila.Profesor
+	actividad.Sala = fila.Sala
+	actividad.FotoURL = fila.FotoURL
+	if fila.ClaveExterna != "" {
+		clave := fila.ClaveExterna
+		actividad.ClaveExterna = &clave
+	}
+
+	if !existe {
+		resultado.Creadas++
+		return tx.Create(&actividad).Error
+	}
+
+	restaurada := actividad.DeletedAt.Valid
+	actividad.DeletedAt = gorm.DeletedAt{}
+	if !restaurada && mismosDatos(anterior, actividad) {
+		resultado.SinCambios++
+		return nil
+	}
+
+	resultado.Actualizadas++
+	if err := tx.Unscoped().Save(&actividad).Error; err != nil {
+		return err
+	}
+	return NotificarCambioActividad(tx, &actividad, false)
+}
+
+func mismosDatos(a, b models.Actividad) bool {
+	claveA, claveB := "", ""
+	if a.ClaveExterna != nil {
+		claveA = *a.ClaveExterna
+	}
+	if b.ClaveExterna != nil {
+		claveB = *b.ClaveExterna
+	}
+	return claveA == claveB && a.Titulo == b.Titulo && a.Categoria == b.Categoria &&
+		a.Descripcion == b.Descripcion && a.Dia == b.Dia && a.Horario == b.Horario &&
+		a.DuracionMinutos == b.DuracionMinutos && a.CupoMaximo == b.CupoMaximo &&
+		a.Profesor == b.Profesor && a.Sala == b.Sala && a.FotoURL == b.FotoURL
+}
+
+func leerRegistros(r io.Reader, formato string) ([][]string, error) {
+	switch formato {
+	case FormatoCSV:
+		lector := csv.NewReader(r)
+		lector.FieldsPerRecord = -1
+		lector.TrimLeadingSpace = true
+		registros, err := lector.ReadAll()
+		if err != nil {
+			return nil, ErrArchivoInvalido.ConCausa(err)
+		}
+		// Quitar el BOM que agregan algunas planillas al guardar en CSV
+		if len(registros) > 0 && len(registros[0]) > 0 {
+			registros[0][0] = strings.TrimPrefix(registros[0][0], "\ufeff")
+		}
+		return registros, nil
+
+	case FormatoXLSX:
+		libro, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, ErrArchivoInvalido.ConCausa(err)
+		}
+		defer libro.Close()
+		registros, err := libro.GetRows(libro.GetSheetName(0))
+		if err != nil {
+			return nil, ErrArchivoInvalido.ConCausa(err)
+		}
+		return registros, nil
+
+	default:
+		return nil, ErrFormatoNoSoportado
+	}
+}
+
+// ExportarActividades genera el archivo de actividades con las mismas
+// columnas que acepta la importación.
+func ExportarActividades(formato string) ([]byte, error) {
+	var actividades []models.Actividad
+	if err := config.DB.Order("id").Find(&actividades).Error; err != nil {
+		return nil, err
+	}
+
+	registros := [][]string{ColumnasActividad}
+	for _, a := range actividades {
+		clave := ""
+		if a.ClaveExterna != nil {
+			clave = *a.ClaveExterna
+		}
+		registros = append(registros, []string{
+			strconv.FormatUint(uint64(a.ID), 10), clave, a.Titulo, a.Categoria, a.Descripcion, a.Dia, a.Horario,
+			strconv.Itoa(a.DuracionMinutos), strconv.Itoa(a.CupoMaximo), a.Profesor, a.Sala, a.FotoURL,
+		})
+	}
+
+	var buf bytes.Buffer
+	switch formato {
+	case FormatoCSV:
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(registros); err != nil {
+			return nil, err
+		}
+
+	case FormatoXLSX:
+		libro := excelize.NewFile()
+		defer libro.Close()
+		hoja := libro.GetSheetName(0)
+		for i, registro := range registros {
+			celda, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			fila := make([]interface{}, len(registro))
+			for j, valor := range registro {
+				fila[j] = valor
+			}
+			if err := libro.SetSheetRow(hoja, celda, &fila); err != nil {
+				return nil, err
+			}
+		}
+		if err := libro.Write(&buf); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, ErrFormatoNoSoportado
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+const encabezadoImportacion = "id,clave_externa,titulo,categoria,descripcion,dia,horario,duracion_minutos,cupo_maximo,profesor,sala,foto_url\n"
+
+// importarCSV importa las filas recibidas debajo del encabezado completo.
+func importarCSV(t *testing.T, simular bool, filas ...string) *ResultadoImportacion {
+	t.Helper()
+	archivo := encabezadoImportacion + strings.Join(filas, "\n")
+	resultado, err := ImportarActividades(strings.NewReader(archivo), FormatoCSV, simular)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resultado
+}
+
+func TestImportarActividades(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		simular bool
+		filas   []string
+		// creadas, actualizadas y sin cambios informados
+		creadas, actualizadas, sinCambios int
+		errores                           map[int]string // fila -> campo con error
+		aplicada                          bool
+		total                             int64 // actividades tras importar
+		titulo                            map[uint]string
+		clave                             map[uint]string
+	}{
+		{
+			nombre:   "crea las filas sin id ni clave",
+			filas:    []string{",,Pilates,General,,Martes,09:00,60,10,Ana,,", ",nueva,Spinning,Cardio,,Jueves,19:00,45,20,Luis,,"},
+			creadas:  2,
+			aplicada: true,
+			total:    4,
+		},
+		{
+			nombre:       "actualiza por clave",
+			filas:        []string{",yoga-1,Yoga Flow,General,,Lunes,18:00,60,12,Profe,,"},
+			actualizadas: 1,
+			aplicada:     true,
+			total:        2,
+			titulo:       map[uint]string{1: "Yoga Flow"},
+		},
+		{
+			nombre:       "actualiza por id",
+			filas:        []string{"2,,Boxeo Pro,General,,Miércoles,20:00,60,8,Profe,,"},
+			actualizadas: 1,
+			aplicada:     true,
+			total:        2,
+			titulo:       map[uint]string{2: "Boxeo Pro"},
+		},
+		{
+			nombre:       "id con una clave nueva actualiza y asigna la clave",
+			filas:        []string{"2,boxeo-1,Boxeo,General,,Miércoles,20:00,60,8,Profe,,"},
+			actualizadas: 1,
+			aplicada:     true,
+			total:        2,
+			clave:        map[uint]string{2: "boxeo-1"},
+		},
+		{
+			nombre:     "fila idéntica no cambia",
+			filas:      []string{"1,yoga-1,Yoga,General,,Lunes,18:00,60,10,Profe,,"},
+			sinCambios: 1,
+			aplicada:   true,
+			total:      2,
+		},
+		{
+			nombre:  "id y clave de actividades distintas",
+			filas:   []string{"2,yoga-1,Boxeo,General,,Miércoles,20:00,60,8,Profe,,"},
+			errores: map[int]string{2: "clave_externa"},
+			total:   2,
+			titulo:  map[uint]string{1: "Yoga", 2: "Boxeo"},
+		},
+		{
+			nombre:  "id inexistente",
+			filas:   []string{"99,,Funcional,General,,Viernes,08:00,60,8,Profe,,"},
+			errores: map[int]string{2: "id"},
+			total:   2,
+		},
+		{
+			nombre: "un error por fila y ningún cambio",
+			filas: []string{
+				",,Pilates,General,,Martes,09:00,60,10,Ana,,",
+				",,Zumba,General,,Domingo,10:00,60,0,Ana,,",
+				",,Stretching,General,,Lunes,25:00,60,10,Ana,,",
+				"1,yoga-1,Yoga Flow,General,,Lunes,18:00,60,12,Profe,,",
+			},
+			creadas:      1,
+			actualizadas: 1,
+			errores:      map[int]string{3: "cupo_maximo", 4: "horario"},
+			total:        2,
+			titulo:       map[uint]string{1: "Yoga"},
+		},
+		{
+			nombre:       "la simulación informa y revierte",
+			simular:      true,
+			filas:        []string{",,Pilates,General,,Martes,09:00,60,10,Ana,,", "1,yoga-1,Yoga Flow,General,,Lunes,18:00,60,12,Profe,,"},
+			creadas:      1,
+			actualizadas: 1,
+			total:        2,
+			titulo:       map[uint]string{1: "Yoga"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			yoga := nuevaActividad("Yoga", "Lunes", "18:00", 10)
+			clave := "yoga-1"
+			yoga.ClaveExterna = &clave
+			crear(t, yoga, nuevaActividad("Boxeo", "Miércoles", "20:00", 8))
+
+			resultado := importarCSV(t, caso.simular, caso.filas...)
+			if resultado.Creadas != caso.creadas || resultado.Actualizadas != caso.actualizadas || resultado.SinCambios != caso.sinCambios {
+				t.Errorf("creadas/actualizadas/sin cambios = %d/%d/%d, se esperaba %d/%d/%d",
+					resultado.Creadas, resultado.Actualizadas, resultado.SinCambios,
+					caso.creadas, caso.actualizadas, caso.sinCambios)
+			}
+			if resultado.Aplicada != caso.aplicada || resultado.Simulacion != caso.simular {
+				t.Errorf("aplicada = %v, simulación = %v; se esperaba %v, %v",
+					resultado.Aplicada, resultado.Simulacion, caso.aplicada, caso.simular)
+			}
+			if resultado.Filas != len(caso.filas) {
+				t.Errorf("filas = %d, se esperaban %d", resultado.Filas, len(caso.filas))
+			}
+
+			if len(resultado.Errores) != len(caso.errores) {
+				t.Fatalf("errores = %+v, se esperaban en las filas %v", resultado.Errores, caso.errores)
+			}
+			for _, errorFila := range resultado.Errores {
+				campo, ok := caso.errores[errorFila.Fila]
+				if !ok || len(errorFila.Campos) != 1 || errorFila.Campos[0].Campo != campo {
+					t.Errorf("error inesperado en la fila %d: %+v", errorFila.Fila, errorFila.Campos)
+				}
+			}
+
+			var total int64
+			config.DB.Model(&models.Actividad{}).Count(&total)
+			if total != caso.total {
+				t.Errorf("hay %d actividades, se esperaban %d", total, caso.total)
+			}
+			for id, titulo := range caso.titulo {
+				var actividad models.Actividad
+				config.DB.First(&actividad, id)
+				if actividad.Titulo != titulo {
+					t.Errorf("actividad %d: título %q, se esperaba %q", id, actividad.Titulo, titulo)
+				}
+			}
+			for id, clave := range caso.clave {
+				var actividad models.Actividad
+				config.DB.First(&actividad, id)
+				if actividad.ClaveExterna == nil || *actividad.ClaveExterna != clave {
+					t.Errorf("actividad %d: clave %v, se esperaba %q", id, actividad.ClaveExterna, clave)
+				}
+			}
+		})
+	}
+}
+
+func TestImportarActividadesArchivoInvalido(t *testing.T) {
+	prepararDB(t)
+
+	casos := []struct {
+		nombre  string
+		archivo string
+		formato string
+		err     error
+	}{
+		{"faltan columnas", "titulo,dia\nYoga,Lunes\n", FormatoCSV, ErrColumnasFaltantes},
+		{"vacío", "", FormatoCSV, ErrColumnasFaltantes},
+		{"csv mal formado", "titulo,\"dia\nYoga", FormatoCSV, ErrArchivoInvalido},
+		{"xlsx corrupto", "no es un xlsx", FormatoXLSX, ErrArchivoInvalido},
+		{"formato desconocido", "", "ods", ErrFormatoNoSoportado},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if _, err := ImportarActividades(strings.NewReader(caso.archivo), caso.formato, false); !errors.Is(err, caso.err) {
+				t.Fatalf("err = %v, se esperaba %v", err, caso.err)
+			}
+		})
+	}
+}
+
+// Un archivo exportado se puede volver a importar sin cambios, y con claves
+// agregadas a mano actualiza las mismas actividades en lugar de duplicarlas.
+func TestExportarEImportarActividades(t *testing.T) {
+	for _, formato := range []string{FormatoCSV, FormatoXLSX} {
+		t.Run(formato, func(t *testing.T) {
+			prepararDB(t)
+			crear(t, nuevaActividad("Yoga", "Lunes", "18:00", 10), nuevaActividad("Boxeo, nivel 1", "Miércoles", "20:00", 8))
+
+			archivo, err := ExportarActividades(formato)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resultado, err := ImportarActividades(bytes.NewReader(archivo), formato, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resultado.SinCambios != 2 || resultado.Creadas != 0 || resultado.Actualizadas != 0 || len(resultado.Errores) != 0 {
+				t.Fatalf("reimportar lo exportado: %+v", resultado)
+			}
+		})
+	}
+
+	prepararDB(t)
+	crear(t, nuevaActividad("Yoga", "Lunes", "18:00", 10))
+	archivo, err := ExportarActividades(FormatoCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conClave := strings.Replace(string(archivo), "\n1,,", "\n1,yoga-1,", 1)
+	resultado, err := ImportarActividades(strings.NewReader(conClave), FormatoCSV, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	config.DB.Model(&models.Actividad{}).Count(&total)
+	if resultado.Actualizadas != 1 || total != 1 {
+		t.Fatalf("agregar la clave: %+v, %d actividades", resultado, total)
+	}
+}
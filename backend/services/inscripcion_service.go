@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMotivoSobrecupo = apperrors.Invalido("MOTIVO_SOBRECUPO_REQUERIDO", "Indica el motivo para superar el cupo")
+	ErrMismaActividad  = apperrors.Invalido("MISMA_ACTIVIDAD", "La inscripción ya es de esa actividad")
+)
+
+// InscripcionAdminRequest es la inscripción que carga un administrador.
+// Con ForzarCupo se puede superar el cupo máximo indicando el motivo.
+type InscripcionAdminRequest struct {
+	UsuarioID  uint   `json:"usuario_id" binding:"required"`
+	ForzarCupo bool   `json:"forzar_cupo"`
+	Motivo     string `json:"motivo"`
+}
+
+type MoverInscripcionRequest struct {
+	ActividadID uint   `json:"actividad_id" binding:"required"`
+	ForzarCupo  bool   `json:"forzar_cupo"`
+	Motivo      string `json:"motivo"`
+}
+
+// GetInscripcionesActividad devuelve los inscritos de una actividad con sus datos.
+func GetInscripcionesActividad(actividadID uint) ([]models.Inscripcion, error) {
+	if err := config.DB.First(&models.Actividad{}, actividadID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrActividadNoEncontrada
+		}
+		return nil, err
+	}
+
+	var inscripciones []models.Inscripcion
+	if err := config.DB.Preload("Usuario").Where("actividad_id = ?", actividadID).
+		Order("fecha_inscripcion").Find(&inscripciones).Error; err != nil {
+		return nil, err
+	}
+	return inscripciones, nil
+}
+
+// InscribirComoAdmin inscribe a un socio en una actividad desde recepción.
+func InscribirComoAdmin(adminID, actividadID uint, req InscripcionAdminRequest) (*models.Inscripcion, error) {
+	if req.ForzarCupo && req.Motivo == "" {
+		return nil, ErrMotivoSobrecupo
+	}
+
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, req.UsuarioID).Error; err != nil {
+		return nil, ErrUsuarioNoEncontrado.ConDetalle("usuario_id", req.UsuarioID)
+	}
+	if usuario.SuspendidoAt != nil {
+		return nil, ErrCuentaSuspendida
+	}
+
+	var inscripcion *models.Inscripcion
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		i, err := inscribir(tx, adminID, usuario.ID, actividadID, req.ForzarCupo, req.Motivo)
+		inscripcion = i
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.InscripcionesCreadas.Inc()
+	PublicarCupo(actividadID)
+	config.DB.Preload("Usuario").Preload("Actividad").First(inscripcion, inscripcion.ID)
+	return inscripcion, nil
+}
+
+// MoverInscripcion pasa una inscripción a otra actividad. La baja y el alta
+// se hacen en la misma transacción: si el destino no tiene lugar, el socio
+// conserva su inscripción original.
+func MoverInscripcion(adminID, inscripcionID uint, req MoverInscripcionRequest) (*models.Inscripcion, error) {
+	if req.ForzarCupo && req.Motivo == "" {
+		return nil, ErrMotivoSobrecupo
+	}
+
+	var nueva *models.Inscripcion
+	var origenID uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var original models.Inscripcion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Actividad").
+			First(&original, inscripcionID).Error; err != nil {
+			return ErrInscripcionNoEncontrada
+		}
+		if original.ActividadID == req.ActividadID {
+			return ErrMismaActividad
+		}
+		origenID = original.ActividadID
+
+		if err := tx.Delete(&original).Error; err != nil {
+			return err
+		}
+		if err := EncolarNotificacion(tx, original.UsuarioID, PlantillaInscripcionCancelada, map[string]interface{}{
+			"Actividad": original.Actividad,
+		}); err != nil {
+			return err
+		}
+
+		i, err := inscribir(tx, adminID, original.UsuarioID, req.ActividadID, req.ForzarCupo, req.Motivo)
+		nueva = i
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	PublicarCupo(origenID, req.ActividadID)
+	config.DB.Preload("Usuario").Preload("Actividad").First(nueva, nueva.ID)
+	return nueva, nil
+}
+
+// inscribir crea la inscripción dentro de la transacción bloqueando la
+// actividad, para que dos altas simultáneas no superen el cupo.
+func inscribir(tx *gorm.DB, adminID, usuarioID, actividadID uint, forzarCupo bool, motivo string) (*models.Inscripcion, error) {
+	var actividad models.Actividad
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&actividad, actividadID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrActividadNoEncontrada.ConDetalle("actividad_id", actividadID)
+		}
+		return nil, err
+	}
+
+	var existentes int64
+	tx.Model(&models.Inscripcion{}).
+		Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividad.ID).
+		Count(&existentes)
+	if existentes > 0 {
+		return nil, ErrInscripcionRepetida
+	}
+
+	var inscripcionesCount int64
+	tx.Model(&models.Inscripcion{}).Where("actividad_id = ?", actividad.ID).Count(&inscripcionesCount)
+	sobrecupo := int(inscripcionesCount) >= actividad.CupoMaximo
+	if sobrecupo && !forzarCupo {
+		return nil, ErrActividadSinCupo
+	}
+
+	ahora := time.Now()
+	inscripcion := models.Inscripcion{
+		UsuarioID:        usuarioID,
+		ActividadID:      actividad.ID,
+		FechaInscripcion: &ahora,
+		InscritoPor:      &adminID,
+	}
+	if sobrecupo {
+		inscripcion.MotivoSobrecupo = motivo
+	}
+	if err := tx.Create(&inscripcion).Error; err != nil {
+		return nil, err
+	}
+
+	if err := EncolarNotificacion(tx, usuarioID, PlantillaInscripcionConfirmada, map[string]interface{}{
+		"Actividad": actividad,
+	}); err != nil {
+		return nil, err
+	}
+	return &inscripcion, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ámbitos de los límites de tasa. Cada uno tiene su propia regla.
+const (
+	LimiteLoginIP      = "login_ip"
+	LimiteLoginCuenta  = "login_cuenta"
+	LimiteRecuperacion = "recuperacion"
+	LimiteVerificacion = "verificacion"
+)
+
+var ErrDemasiadosIntentos = apperrors.New(http.StatusTooManyRequests, "DEMASIADOS_INTENTOS",
+	"Demasiados intentos. Intenta de nuevo en {reintentar_en} segundos")
+
+// ReglaLimite describe una cubeta de fichas: admite ráfagas de hasta
+// Capacidad peticiones y recupera PorMinuto fichas por minuto.
+type ReglaLimite struct {
+	Capacidad int
+	PorMinuto int
+}
+
+// ReglaDeLimite devuelve la regla configurada para un ámbito.
+func ReglaDeLimite(ambito string) ReglaLimite {
+	cfg := config.App.Limites
+	switch ambito {
+	case LimiteLoginCuenta:
+		return ReglaLimite{Capacidad: cfg.LoginCuentaCapacidad, PorMinuto: cfg.LoginCuentaPorMinuto}
+	case LimiteRecuperacion:
+		return ReglaLimite{Capacidad: cfg.RecuperacionCapacidad, PorMinuto: cfg.RecuperacionPorMinuto}
+	case LimiteVerificacion:
+		return ReglaLimite{Capacidad: cfg.VerificacionCapacidad, PorMinuto: cfg.VerificacionPorMinuto}
+	default:
+		return ReglaLimite{Capacidad: cfg.LoginIPCapacidad, PorMinuto: cfg.LoginIPPorMinuto}
+	}
+}
+
+// lleno es el tiempo que tarda una cubeta vacía en volver a llenarse; pasado
+// ese tiempo sin uso ya no hace falta guardarla.
+func (r ReglaLimite) lleno() time.Duration {
+	return time.Duration(float64(r.Capacidad) / float64(r.PorMinuto) * float64(time.Minute))
+}
+
+// recargar calcula las fichas de una cubeta después de transcurrido.
+func (r ReglaLimite) recargar(fichas float64, transcurrido time.Duration) float64 {
+	return math.Min(float64(r.Capacidad), fichas+transcurrido.Minutes()*float64(r.PorMinuto))
+}
+
+// espera es cuánto falta para que la cubeta tenga una ficha.
+func (r ReglaLimite) espera(fichas float64) time.Duration {
+	return time.Duration((1 - fichas) / float64(r.PorMinuto) * float64(time.Minute))
+}
+
+// AlmacenLimites guarda las cubetas de fichas. Consumir descuenta una ficha
+// de la cubeta de la clave; si no quedan devuelve permitido = false y el
+// tiempo hasta que haya una disponible.
+type AlmacenLimites interface {
+	Consumir(clave string, regla ReglaLimite, ahora time.Time) (permitido bool, espera time.Duration, err error)
+	Reiniciar(clave string) error
+	// Purgar borra las cubetas sin uso desde antes de la fecha indicada.
+	Purgar(antes time.Time) error
+}
+
+var almacenesLimites = map[string]func() AlmacenLimites{
+	"memoria": func() AlmacenLimites {
+		return NewLimitesMemoria()
+	},
+	"sql": func() AlmacenLimites {
+		return &LimitesSQL{}
+	},
+}
+
+var almacenLimites AlmacenLimites = NewLimitesMemoria()
+
+// RegistrarAlmacenLimites permite agregar almacenes externos (Redis...) sin tocar el servicio.
+func RegistrarAlmacenLimites(nombre string, constructor func() AlmacenLimites) {
+	almacenesLimites[nombre] = constructor
+}
+
+func InitLimites() error {
+	nombre := config.App.Limites.Almacen
+	constructor, ok := almacenesLimites[nombre]
+	if !ok {
+		return fmt.Errorf("almacén de límites desconocido: %s", nombre)
+	}
+	almacenLimites = constructor()
+	return nil
+}
+
+// ConsumirLimite descuenta una ficha del ámbito para la clave (una IP, un
+// email...). Si el almacén falla se deja pasar la petición: es preferible a
+// bloquear el login de todos.
+func ConsumirLimite(ambito, clave string) (bool, time.Duration) {
+	permitido, espera, err := almacenLimites.Consumir(ambito+":"+clave, ReglaDeLimite(ambito), time.Now())
+	if err != nil {
+		slog.Error("error consultando el límite de tasa", "ambito", ambito, "error", err)
+		return true, 0
+	}
+	return permitido, espera
+}
+
+// ReiniciarLimite devuelve la cubeta de la clave a su capacidad completa.
+func ReiniciarLimite(ambito, clave string) error {
+	return almacenLimites.Reiniciar(ambito + ":" + clave)
+}
+
+// IniciarLimpiezaLimites borra cada hora las cubetas que ya se recargaron y
+// los bloqueos de login de emails sin cuenta ya vencidos.
+func IniciarLimpiezaLimites(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Ninguna regla tarda más que la más lenta en llenarse
+			espera := ReglaDeLimite(LimiteLoginIP).lleno()
+			if cuenta := ReglaDeLimite(LimiteLoginCuenta).lleno(); cuenta > espera {
+				espera = cuenta
+			}
+			if err := almacenLimites.Purgar(time.Now().Add(-espera)); err != nil {
+				slog.Error("error limpiando cubetas de límites", "error", err)
+			}
+			maximo := time.Duration(config.App.Limites.BloqueoMaxMinutos) * time.Minute
+			if err := PurgarBloqueosLogin(time.Now().Add(-maximo)); err != nil {
+				slog.Error("error limpiando bloqueos de login", "error", err)
+			}
+		}
+	}
+}
+
+type cubeta struct {
+	fichas        float64
+	actualizadaAt time.Time
+}
+
+// LimitesMemoria guarda las cubetas en el proceso. Cada instancia lleva su
+// propia cuenta, así que con varias el límite efectivo se multiplica.
+type LimitesMemoria struct {
+	mu      sync.Mutex
+	cubetas map[string]*cubeta
+}
+
+func NewLimitesMemoria() *LimitesMemoria {
+	return &LimitesMemoria{cubetas: map[string]*cubeta{}}
+}
+
+func (l *LimitesMemoria) Consumir(clave string, regla ReglaLimite, ahora time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, ok := l.cubetas[clave]
+	if !ok {
+		c = &cubeta{fichas: float64(regla.Capacidad), actualizadaAt: ahora}
+		l.cubetas[clave] = c
+	}
+	c.fichas = regla.recargar(c.fichas, ahora.Sub(c.actualizadaAt))
+	c.actualizadaAt = ahora
+
+	if c.fichas < 1 {
+		return false, regla.espera(c.fichas), nil
+	}
+	c.fichas--
+	return true, 0, nil
+}
+
+func (l *LimitesMemoria) Reiniciar(clave string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cubetas, clave)
+	return nil
+}
+
+func (l *LimitesMemoria) Purgar(antes time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for clave, c := range l.cubetas {
+		if c.actualizadaAt.Before(antes) {
+			delete(l.cubetas, clave)
+		}
+	}
+	return nil
+}
+
+// LimitesSQL guarda las cubetas en la tabla cubeta_limites para que todas
+// las instancias compartan el mismo límite.
+type LimitesSQL struct{}
+
+func (LimitesSQL) Consumir(clave string, regla ReglaLimite, ahora time.Time) (bool, time.Duration, error) {
+	permitido := false
+	var espera time.Duration
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Crear la cubeta llena si no existe, y después bloquearla
+		nueva := models.CubetaLimite{Clave: clave, Fichas: float64(regla.Capacidad), ActualizadaAt: ahora}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&nueva).Error; err != nil {
+			return err
+		}
+		var c models.CubetaLimite
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("clave = ?", clave).First(&c).Error; err != nil {
+			return err
+		}
+
+		c.Fichas = regla.recargar(c.Fichas, ahora.Sub(c.ActualizadaAt))
+		c.ActualizadaAt = ahora
+		if c.Fichas < 1 {
+			espera = regla.espera(c.Fichas)
+		} else {
+			c.Fichas--
+			permitido = true
+		}
+		return tx.Save(&c).Error
+	})
+	return permitido, espera, err
+}
+
+func (LimitesSQL) Reiniciar(clave string) error {
+	return config.DB.Where("clave = ?", clave).Delete(&models.CubetaLimite{}).Error
+}
+
+func (LimitesSQL) Purgar(antes time.Time) error {
+	return config.DB.Where("actualizada_at < ?", antes).Delete(&models.CubetaLimite{}).Error
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestReglaLimite(t *testing.T) {
+	regla := ReglaLimite{Capacidad: 5, PorMinuto: 2}
+
+	if lleno := regla.lleno(); lleno != 150*time.Second {
+		t.Errorf("lleno() = %v, se esperaba 2m30s", lleno)
+	}
+
+	recargas := []struct {
+		fichas       float64
+		transcurrido time.Duration
+		esperado     float64
+	}{
+		{0, 0, 0},
+		{0, 30 * time.Second, 1},
+		{1.5, time.Minute, 3.5},
+		{4, 10 * time.Minute, 5},
+	}
+	for _, caso := range recargas {
+		if got := regla.recargar(caso.fichas, caso.transcurrido); got != caso.esperado {
+			t.Errorf("recargar(%v, %v) = %v, se esperaba %v", caso.fichas, caso.transcurrido, got, caso.esperado)
+		}
+	}
+
+	esperas := []struct {
+		fichas   float64
+		esperado time.Duration
+	}{
+		{0, 30 * time.Second},
+		{0.5, 15 * time.Second},
+	}
+	for _, caso := range esperas {
+		if got := regla.espera(caso.fichas); got != caso.esperado {
+			t.Errorf("espera(%v) = %v, se esperaba %v", caso.fichas, got, caso.esperado)
+		}
+	}
+}
+
+// TestAlmacenesLimites recorre la misma secuencia de pedidos contra cada
+// almacén: una ráfaga que agota la cubeta, la recarga con el tiempo, claves
+// independientes y el reinicio.
+func TestAlmacenesLimites(t *testing.T) {
+	almacenes := []struct {
+		nombre string
+		nuevo  func(t *testing.T) AlmacenLimites
+	}{
+		{"memoria", func(t *testing.T) AlmacenLimites { return NewLimitesMemoria() }},
+		{"sql", func(t *testing.T) AlmacenLimites {
+			prepararDB(t)
+			return LimitesSQL{}
+		}},
+	}
+	regla := ReglaLimite{Capacidad: 3, PorMinuto: 1}
+	inicio := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+	pasos := []struct {
+		nombre    string
+		clave     string
+		segundos  int // desde inicio
+		reiniciar bool
+		permitido bool
+		espera    time.Duration
+	}{
+		{"primera", "ip:1", 0, false, true, 0},
+		{"segunda", "ip:1", 0, false, true, 0},
+		{"tercera agota la ráfaga", "ip:1", 0, false, true, 0},
+		{"cuarta se rechaza", "ip:1", 0, false, false, time.Minute},
+		{"a mitad de la recarga", "ip:1", 30, false, false, 30 * time.Second},
+		{"otra clave no se ve afectada", "ip:2", 30, false, true, 0},
+		{"recargada una ficha", "ip:1", 60, false, true, 0},
+		{"vuelve a estar vacía", "ip:1", 61, false, false, 59 * time.Second},
+		{"reiniciada", "ip:1", 62, true, true, 0},
+	}
+
+	for _, almacen := range almacenes {
+		t.Run(almacen.nombre, func(t *testing.T) {
+			a := almacen.nuevo(t)
+			for _, paso := range pasos {
+				if paso.reiniciar {
+					if err := a.Reiniciar(paso.clave); err != nil {
+						t.Fatal(err)
+					}
+				}
+				ahora := inicio.Add(time.Duration(paso.segundos) * time.Second)
+				permitido, espera, err := a.Consumir(paso.clave, regla, ahora)
+				if err != nil {
+					t.Fatalf("%s: %v", paso.nombre, err)
+				}
+				if permitido != paso.permitido || espera.Round(time.Millisecond) != paso.espera {
+					t.Errorf("%s: permitido = %v, espera = %v; se esperaba %v, %v",
+						paso.nombre, permitido, espera, paso.permitido, paso.espera)
+				}
+			}
+
+			// Purgar borra las cubetas sin uso: ip:2 vuelve a empezar llena
+			if err := a.Purgar(inicio.Add(45 * time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < regla.Capacidad; i++ {
+				if permitido, _, _ := a.Consumir("ip:2", regla, inicio.Add(46*time.Second)); !permitido {
+					t.Fatalf("tras purgar, el pedido %d de ip:2 se rechazó", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestIniciarSesionBloqueoProgresivo(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+	limites := config.App.Limites
+
+	vencerBloqueo := func() {
+		config.DB.Model(&models.Usuario{}).Where("id = ?", socio.ID).
+			Update("bloqueado_hasta", time.Now().Add(-time.Second))
+	}
+	bloqueo := func(err error) time.Duration {
+		var usuario models.Usuario
+		config.DB.First(&usuario, socio.ID)
+		if usuario.BloqueadoHasta == nil {
+			t.Fatalf("la cuenta no quedó bloqueada (err = %v)", err)
+		}
+		return time.Until(*usuario.BloqueadoHasta).Round(time.Minute)
+	}
+
+	for i := 1; i < limites.BloqueoIntentos; i++ {
+		if _, err := IniciarSesion(socio.Email, "incorrecta", "10.0.0.1"); !errors.Is(err, ErrCredencialesInvalidas) {
+			t.Fatalf("intento %d: err = %v, se esperaba %v", i, err, ErrCredencialesInvalidas)
+		}
+	}
+
+	// Al llegar al umbral se bloquea, y el bloqueo se duplica con cada nuevo fallo
+	for i, minutos := range []int{limites.BloqueoMinutos, 2 * limites.BloqueoMinutos, 4 * limites.BloqueoMinutos} {
+		_, err := IniciarSesion(socio.Email, "incorrecta", "10.0.0.1")
+		if !errors.Is(err, ErrCuentaBloqueada) {
+			t.Fatalf("fallo %d tras el umbral: err = %v, se esperaba %v", i+1, err, ErrCuentaBloqueada)
+		}
+		if got := bloqueo(err); got != time.Duration(minutos)*time.Minute {
+			t.Errorf("fallo %d tras el umbral: bloqueo de %v, se esperaba %dm", i+1, got, minutos)
+		}
+
+		// Mientras dura el bloqueo ni la contraseña correcta entra
+		if _, err := IniciarSesion(socio.Email, "secreto", "10.0.0.1"); !errors.Is(err, ErrCuentaBloqueada) {
+			t.Fatalf("login bloqueado: err = %v, se esperaba %v", err, ErrCuentaBloqueada)
+		}
+		vencerBloqueo()
+	}
+
+	usuario, err := IniciarSesion(socio.Email, "secreto", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usuario.IntentosFallidos != 0 || usuario.BloqueadoHasta != nil {
+		t.Errorf("el login correcto no reinició el contador: %d intentos, bloqueada hasta %v",
+			usuario.IntentosFallidos, usuario.BloqueadoHasta)
+	}
+}
+
+// Un email sin cuenta recibe las mismas respuestas que una cuenta real, para
+// que el bloqueo no revele qué emails están registrados.
+func TestIniciarSesionNoRevelaCuentas(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+	limites := config.App.Limites
+
+	respuesta := func(email string) string {
+		_, err := IniciarSesion(email, "incorrecta", "10.0.0.1")
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) {
+			t.Fatalf("%s: err = %v, se esperaba un error de la API", email, err)
+		}
+		if hasta, ok := appErr.Detalles["bloqueada_hasta"].(time.Time); ok {
+			return fmt.Sprintf("%s %v", appErr.Codigo, time.Until(hasta).Round(time.Minute))
+		}
+		return appErr.Codigo
+	}
+	vencerBloqueos := func() {
+		vencido := time.Now().Add(-time.Second)
+		config.DB.Model(&models.Usuario{}).Where("id = ?", socio.ID).Update("bloqueado_hasta", vencido)
+		config.DB.Model(&models.BloqueoLogin{}).Where("1 = 1").Update("bloqueado_hasta", vencido)
+	}
+
+	for i := 1; i <= limites.BloqueoIntentos+2; i++ {
+		real, desconocido := respuesta(socio.Email), respuesta("Nadie@Gym.test")
+		if real != desconocido {
+			t.Fatalf("intento %d: cuenta real %q, email sin cuenta %q", i, real, desconocido)
+		}
+		if i >= limites.BloqueoIntentos {
+			// Durante el bloqueo tampoco se distinguen
+			if real, desconocido := respuesta(socio.Email), respuesta("nadie@gym.test"); real != desconocido {
+				t.Fatalf("bloqueado tras el intento %d: cuenta real %q, email sin cuenta %q", i, real, desconocido)
+			}
+			vencerBloqueos()
+		}
+	}
+
+	// Los contadores de emails sin cuenta se purgan una vez vencidos
+	if err := PurgarBloqueosLogin(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	config.DB.Model(&models.BloqueoLogin{}).Count(&total)
+	if total != 0 {
+		t.Errorf("quedaron %d bloqueos de emails sin cuenta", total)
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCuentaBloqueada    = apperrors.New(http.StatusTooManyRequests, "CUENTA_BLOQUEADA", "Tu cuenta está bloqueada temporalmente por demasiados intentos fallidos")
+	ErrUsuarioNoBloqueado = apperrors.Conflicto("USUARIO_NO_BLOQUEADO", "El usuario no está bloqueado")
+)
+
+// IniciarSesion verifica las credenciales aplicando el bloqueo progresivo:
+// cada contraseña incorrecta suma un intento y, alcanzado el umbral, la
+// cuenta queda bloqueada un tiempo que se duplica con cada nuevo fallo. Un
+// login correcto vuelve el contador a cero.
+func IniciarSesion(email, password, ip string) (*models.Usuario, error) {
+	var usuario models.Usuario
+	if err := config.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		metrics.LoginsFallidos.Inc()
+		RegistrarAuditoria(models.AuditoriaLoginFallido, nil, nil, ip, map[string]interface{}{"email": email})
+		return nil, loginFallidoSinCuenta(email, time.Now())
+	}
+
+	ahora := time.Now()
+	if usuario.BloqueadoHasta != nil && usuario.BloqueadoHasta.After(ahora) {
+		return nil, ErrCuentaBloqueada.ConDetalle("bloqueada_hasta", *usuario.BloqueadoHasta)
+	}
+
+	if !passwordCorrecta(&usuario, password) {
+		metrics.LoginsFallidos.Inc()
+		return nil, registrarLoginFallido(&usuario, ip, ahora)
+	}
+
+	if usuario.SuspendidoAt != nil {
+		return nil, ErrCuentaSuspendida
+	}
+
+	if usuario.IntentosFallidos > 0 || usuario.BloqueadoHasta != nil {
+		usuario.IntentosFallidos = 0
+		usuario.BloqueadoHasta = nil
+		if err := config.DB.Model(&usuario).Updates(map[string]interface{}{
+			"intentos_fallidos": 0,
+			"bloqueado_hasta":   nil,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &usuario, nil
+}
+
+// registrarLoginFallido suma el intento y bloquea la cuenta si corresponde.
+// Devuelve el error que recibe el cliente.
+func registrarLoginFallido(usuario *models.Usuario, ip string, ahora time.Time) error {
+	// El incremento se hace en la base para no perder intentos simultáneos
+	if err := config.DB.Model(usuario).UpdateColumn("intentos_fallidos", gorm.Expr("intentos_fallidos + 1")).Error; err != nil {
+		return err
+	}
+	if err := config.DB.Select("intentos_fallidos").First(usuario, usuario.ID).Error; err != nil {
+		return err
+	}
+
+	RegistrarAuditoria(models.AuditoriaLoginFallido, &usuario.ID, nil, ip, map[string]interface{}{
+		"intentos": usuario.IntentosFallidos,
+	})
+
+	hasta, bloquear := finBloqueo(usuario.IntentosFallidos, ahora)
+	if !bloquear {
+		return ErrCredencialesInvalidas
+	}
+	if err := config.DB.Model(usuario).UpdateColumn("bloqueado_hasta", hasta).Error; err != nil {
+		return err
+	}
+
+	RegistrarAuditoria(models.AuditoriaCuentaBloqueada, &usuario.ID, nil, ip, map[string]interface{}{
+		"intentos":        usuario.IntentosFallidos,
+		"bloqueada_hasta": hasta,
+	})
+	return ErrCuentaBloqueada.ConDetalle("bloqueada_hasta", hasta)
+}
+
+// finBloqueo indica si tantos intentos fallidos bloquean la cuenta y hasta
+// cuándo: el bloqueo empieza en el umbral y se duplica con cada nuevo fallo.
+func finBloqueo(intentos int, ahora time.Time) (time.Time, bool) {
+	cfg := config.App.Limites
+	exceso := intentos - cfg.BloqueoIntentos
+	if exceso < 0 {
+		return time.Time{}, false
+	}
+
+	minutos := cfg.BloqueoMaxMinutos
+	if exceso < 20 && cfg.BloqueoMinutos<<exceso < cfg.BloqueoMaxMinutos {
+		minutos = cfg.BloqueoMinutos << exceso
+	}
+	return ahora.Add(time.Duration(minutos) * time.Minute), true
+}
+
+// loginFallidoSinCuenta aplica a un email sin cuenta el mismo bloqueo
+// progresivo que a una cuenta real: si sólo se bloquearan las cuentas
+// reales, la respuesta revelaría qué emails están registrados.
+func loginFallidoSinCuenta(email string, ahora time.Time) error {
+	bloqueo := models.BloqueoLogin{Email: ClaveCuenta(email)}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&bloqueo).Error; err != nil {
+		return err
+	}
+	if err := config.DB.First(&bloqueo, "email = ?", bloqueo.Email).Error; err != nil {
+		return err
+	}
+	if bloqueo.BloqueadoHasta != nil && bloqueo.BloqueadoHasta.After(ahora) {
+		return ErrCuentaBloqueada.ConDetalle("bloqueada_hasta", *bloqueo.BloqueadoHasta)
+	}
+
+	if err := config.DB.Model(&bloqueo).Update("intentos_fallidos", gorm.Expr("intentos_fallidos + 1")).Error; err != nil {
+		return err
+	}
+	if err := config.DB.Select("intentos_fallidos").First(&bloqueo, "email = ?", bloqueo.Email).Error; err != nil {
+		return err
+	}
+
+	hasta, bloquear := finBloqueo(bloqueo.IntentosFallidos, ahora)
+	if !bloquear {
+		return ErrCredencialesInvalidas
+	}
+	if err := config.DB.Model(&bloqueo).Update("bloqueado_hasta", hasta).Error; err != nil {
+		return err
+	}
+	return ErrCuentaBloqueada.ConDetalle("bloqueada_hasta", hasta)
+}
+
+// PurgarBloqueosLogin borra los contadores de emails sin cuenta que no
+// fallan ni están bloqueados desde antes de la fecha indicada. Pasado ese
+// tiempo un email sin cuenta vuelve a empezar de cero, a diferencia de una
+// cuenta real, que conserva sus intentos hasta el próximo login correcto.
+func PurgarBloqueosLogin(antes time.Time) error {
+	return config.DB.Where("updated_at < ? AND (bloqueado_hasta IS NULL OR bloqueado_hasta < ?)", antes, antes).
+		Delete(&models.BloqueoLogin{}).Error
+}
+
+// DesbloquearUsuario quita el bloqueo por intentos fallidos y restablece el
+// límite de intentos de la cuenta.
+func DesbloquearUsuario(adminID, id uint, ip string) (*models.Usuario, error) {
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if usuario.BloqueadoHasta == nil && usuario.IntentosFallidos == 0 {
+		return nil, ErrUsuarioNoBloqueado
+	}
+
+	intentos := usuario.IntentosFallidos
+	if err := config.DB.Unscoped().Model(usuario).Updates(map[string]interface{}{
+		"intentos_fallidos": 0,
+		"bloqueado_hasta":   nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+	usuario.IntentosFallidos = 0
+	usuario.BloqueadoHasta = nil
+
+	if err := ReiniciarLimite(LimiteLoginCuenta, ClaveCuenta(usuario.Email)); err != nil {
+		return nil, err
+	}
+
+	RegistrarAuditoria(models.AuditoriaCuentaDesbloqueada, &usuario.ID, &adminID, ip, map[string]interface{}{
+		"intentos": intentos,
+	})
+	return usuario, nil
+}
+
+// ClaveCuenta normaliza el email para usarlo como clave del límite por
+// cuenta: la búsqueda de usuarios no distingue mayúsculas.
+func ClaveCuenta(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Plantillas de correo disponibles
+const (
+	PlantillaInscripcionConfirmada = "inscripcion_confirmada"
+	PlantillaInscripcionCancelada  = "inscripcion_cancelada"
+	PlantillaActividadModificada   = "actividad_modificada"
+	PlantillaRecordatorioClase     = "recordatorio_clase"
+	PlantillaRecuperarPassword     = "recuperar_password"
+	PlantillaVerificarEmail        = "verificar_email"
+)
+
+//go:embed plantillas/*.tmpl
+var plantillasFS embed.FS
+
+var funcionesPlantilla = map[string]interface{}{
+	"fecha": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("02/01/2006 15:04")
+	},
+	"hora": func(t time.Time) string {
+		return t.Format("15:04")
+	},
+}
+
+var emailSender EmailSender
+
+func InitNotificaciones() error {
+	sender, err := NewEmailSender(config.App.Notificaciones.Sender)
+	if err != nil {
+		return err
+	}
+	emailSender = sender
+	return nil
+}
+
+// RenderPlantilla genera asunto, texto plano y HTML de una plantilla de correo.
+func RenderPlantilla(nombre string, datos map[string]interface{}) (asunto, texto, html string, err error) {
+	txt, err := texttemplate.New(nombre).Funcs(funcionesPlantilla).
+		ParseFS(plantillasFS, "plantillas/"+nombre+".txt.tmpl")
+	if err != nil {
+		return "", "", "", err
+	}
+	htm, err := htmltemplate.New(nombre).Funcs(funcionesPlantilla).
+		ParseFS(plantillasFS, "plantillas/base.html.tmpl", "plantillas/"+nombre+".html.tmpl")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var bufAsunto, bufTexto, bufHTML bytes.Buffer
+	if err := txt.ExecuteTemplate(&bufAsunto, "asunto", datos); err != nil {
+		return "", "", "", err
+	}
+	if err := txt.ExecuteTemplate(&bufTexto, nombre+".txt.tmpl", datos); err != nil {
+		return "", "", "", err
+	}
+	if err := htm.ExecuteTemplate(&bufHTML, "base", datos); err != nil {
+		return "", "", "", err
+	}
+
+	return strings.TrimSpace(bufAsunto.String()), bufTexto.String(), bufHTML.String(), nil
+}
+
+// EncolarNotificacion escribe un correo en la bandeja de salida usando la
+// transacción recibida, de modo que sólo se envíe si el cambio se confirma.
+func EncolarNotificacion(tx *gorm.DB, usuarioID uint, plantilla string, datos map[string]interface{}) error {
+	var usuario models.Usuario
+	if err := tx.First(&usuario, usuarioID).Error; err != nil {
+		return err
+	}
+
+	if datos == nil {
+		datos = map[string]interface{}{}
+	}
+	datos["Nombre"] = usuario.Nombre
+
+	asunto, texto, html, err := RenderPlantilla(plantilla, datos)
+	if err != nil {
+		return fmt.Errorf("error generando plantilla %s: %w", plantilla, err)
+	}
+
+	notificacion := models.Notificacion{
+		UsuarioID:      &usuario.ID,
+		Canal:          "email",
+		Plantilla:      plantilla,
+		Destinatario:   usuario.Email,
+		Asunto:         asunto,
+		CuerpoTexto:    texto,
+		CuerpoHTML:     html,
+		Estado:         models.NotificacionPendiente,
+		ProximoIntento: time.Now(),
+	}
+	return tx.Create(&notificacion).Error
+}
+
+// IniciarWorkerNotificaciones entrega periódicamente los correos pendientes
+// hasta que se cancele el contexto.
+func IniciarWorkerNotificaciones(ctx context.Context) {
+	intervalo := time.Duration(config.App.Notificaciones.IntervaloSegundos) * time.Second
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := procesarNotificacionesPendientes(ctx); err != nil {
+				slog.Error("error procesando notificaciones", "error", err)
+			}
+		}
+	}
+}
+
+func procesarNotificacionesPendientes(ctx context.Context) error {
+	lote := config.App.Notificaciones.Lote
+	var notificaciones []models.Notificacion
+
+	// Reservar el lote moviendo el próximo intento, para que otra instancia
+	// del worker no tome los mismos mensajes mientras se envían.
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("estado = ? AND proximo_intento <= ?", models.NotificacionPendiente, time.Now()).
+			Order("id").Limit(lote).Find(&notificaciones).Error; err != nil {
+			return err
+		}
+		if len(notificaciones) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(notificaciones))
+		for i := range notificaciones {
+			ids[i] = notificaciones[i].ID
+		}
+		return tx.Model(&models.Notificacion{}).Where("id IN ?", ids).
+			Update("proximo_intento", time.Now().Add(time.Minute)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range notificaciones {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// Sin poder registrar los envíos se corta el lote, para no duplicar más correos
+		if err := entregarNotificacion(ctx, &notificaciones[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entregarNotificacion envía un correo y guarda el resultado. Si no se puede
+// guardar, el mensaje vuelve a quedar pendiente y podría reenviarse.
+func entregarNotificacion(ctx context.Context, notificacion *models.Notificacion) error {
+	maxIntentos := config.App.Notificaciones.MaxIntentos
+	notificacion.Intentos++
+
+	if err := emailSender.Enviar(ctx, notificacion); err != nil {
+		notificacion.UltimoError = err.Error()
+		if notificacion.Intentos >= maxIntentos {
+			notificacion.Estado = models.NotificacionFallida
+		} else {
+			notificacion.ProximoIntento = time.Now().Add(backoffNotificacion(notificacion.Intentos))
+		}
+		slog.Warn("error enviando notificación", "notificacion_id", notificacion.ID,
+			"intento", notificacion.Intentos, "error", err)
+	} else {
+		now := time.Now()
+		notificacion.Estado = models.NotificacionEnviada
+		notificacion.EnviadaAt = &now
+		notificacion.UltimoError = ""
+	}
+
+	if err := config.DB.Save(notificacion).Error; err != nil {
+		return fmt.Errorf("guardando el estado de la notificación %d (%s): %w",
+			notificacion.ID, notificacion.Estado, err)
+	}
+	return nil
+}
+
+// backoffNotificacion duplica la espera entre reintentos, con un máximo de una hora.
+func backoffNotificacion(intentos int) time.Duration {
+	espera := 30 * time.Second
+	for i := 1; i < intentos && espera < time.Hour; i++ {
+		espera *= 2
+	}
+	if espera > time.Hour {
+		espera = time.Hour
+	}
+	return espera
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+// ProveedorPagos abstrae la pasarela que efectivamente cobra al socio.
+// Cada implementación crea el cobro y valida las notificaciones (webhooks)
+// que la pasarela envía cuando el pago cambia de estado.
+type ProveedorPagos interface {
+	Nombre() string
+	CrearCobro(pago *models.Pago) (*Cobro, error)
+	ParsearWebhook(payload []byte, firma string) (*EventoPago, error)
+	// Reembolsar pide a la pasarela que devuelva el cobro. Si la devolución
+	// se resuelve en el momento devuelve el evento con el resultado; si no,
+	// devuelve nil y la pasarela la confirmará después con un webhook.
+	Reembolsar(pago *models.Pago) (*EventoPago, error)
+}
+
+type Cobro struct {
+	ReferenciaExterna string
+	URLPago           string
+}
+
+type EventoPago struct {
+	ReferenciaExterna string `json:"referencia"`
+	Estado            string `json:"estado"`
+}
+
+var (
+	ErrWebhookInvalido           = apperrors.NoAutorizado("WEBHOOK_INVALIDO", "Webhook inválido")
+	ErrPasarelaFakeDeshabilitada = apperrors.NoEncontrado("PASARELA_FAKE_DESHABILITADA", "Pasarela fake deshabilitada")
+	ErrPagosDeshabilitados       = apperrors.New(http.StatusServiceUnavailable, "PAGOS_DESHABILITADOS", "Los pagos en línea no están habilitados")
+)
+
+var proveedoresPagos = map[string]func() ProveedorPagos{
+	"fake": func() ProveedorPagos {
+		return NewProveedorFake(config.App.Pagos.WebhookSecreto)
+	},
+}
+
+var proveedorPagos ProveedorPagos
+
+// RegistrarProveedorPagos permite agregar pasarelas reales sin tocar el servicio de pagos.
+func RegistrarProveedorPagos(nombre string, constructor func() ProveedorPagos) {
+	proveedoresPagos[nombre] = constructor
+}
+
+// InitPagos crea la pasarela configurada. Sin PAGOS_PROVEEDOR los pagos en
+// línea quedan deshabilitados y el resto de la API funciona igual.
+func InitPagos() error {
+	nombre := config.App.Pagos.Proveedor
+	if nombre == "" {
+		proveedorPagos = nil
+		slog.Warn("pagos en línea deshabilitados: no hay PAGOS_PROVEEDOR configurado")
+		return nil
+	}
+	constructor, ok := proveedoresPagos[nombre]
+	if !ok {
+		return fmt.Errorf("proveedor de pagos desconocido: %s", nombre)
+	}
+	proveedorPagos = constructor()
+	return nil
+}
+
+// GetProveedorPagos devuelve la pasarela configurada, o ErrPagosDeshabilitados
+// si no hay ninguna.
+func GetProveedorPagos() (ProveedorPagos, error) {
+	if proveedorPagos == nil {
+		return nil, ErrPagosDeshabilitados
+	}
+	return proveedorPagos, nil
+}
+
+// ProveedorFake simula una pasarela de pagos para desarrollo: no cobra nada,
+// pero firma sus webhooks igual que lo haría un proveedor real.
+type ProveedorFake struct {
+	secreto []byte
+}
+
+func NewProveedorFake(secreto string) *ProveedorFake {
+	return &ProveedorFake{secreto: []byte(secreto)}
+}
+
+func (p *ProveedorFake) Nombre() string {
+	return "fake"
+}
+
+func (p *ProveedorFake) CrearCobro(pago *models.Pago) (*Cobro, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	referencia := "fake_" + hex.EncodeToString(b)
+
+	return &Cobro{
+		ReferenciaExterna: referencia,
+		URLPago:           "/api/v1/pagos/fake/checkout/" + referencia,
+	}, nil
+}
+
+func (p *ProveedorFake) ParsearWebhook(payload []byte, firma string) (*EventoPago, error) {
+	if !hmac.Equal([]byte(firma), []byte(p.Firmar(payload))) {
+		return nil, ErrWebhookInvalido.ConCausa(fmt.Errorf("firma de webhook inválida"))
+	}
+
+	var evento EventoPago
+	if err := json.Unmarshal(payload, &evento); err != nil {
+		return nil, ErrWebhookInvalido.ConCausa(fmt.Errorf("payload de webhook inválido: %w", err))
+	}
+	return &evento, nil
+}
+
+// Reembolsar confirma la devolución en el acto: la pasarela fake no cobró nada.
+func (p *ProveedorFake) Reembolsar(pago *models.Pago) (*EventoPago, error) {
+	return &EventoPago{ReferenciaExterna: pago.ReferenciaExterna, Estado: models.PagoReembolsado}, nil
+}
+
+// Firmar calcula la firma HMAC-SHA256 que acompaña a cada webhook.
+func (p *ProveedorFake) Firmar(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secreto)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Plan struct {
+	Nombre       string `json:"nombre"`
+	Monto        int64  `json:"monto"` // en centavos
+	DuracionDias int    `json:"duracion_dias"`
+}
+
+var Planes = map[string]Plan{
+	"mensual":    {Nombre: "mensual", Monto: 2500000, DuracionDias: 30},
+	"trimestral": {Nombre: "trimestral", Monto: 6750000, DuracionDias: 90},
+	"anual":      {Nombre: "anual", Monto: 24000000, DuracionDias: 365},
+}
+
+var (
+	ErrConceptoInvalido    = apperrors.Invalido("CONCEPTO_INVALIDO", "Concepto de pago inválido")
+	ErrPlanInexistente     = apperrors.Invalido("PLAN_INEXISTENTE", "Plan inexistente")
+	ErrTransicionInvalida  = apperrors.Conflicto("TRANSICION_INVALIDA", "Transición de estado inválida")
+	ErrPagoNoEncontrado    = apperrors.NoEncontrado("PAGO_NO_ENCONTRADO", "Pago no encontrado")
+	ErrActividadSinCupo    = apperrors.Conflicto("ACTIVIDAD_SIN_CUPO", "No hay cupo disponible para esta actividad")
+	ErrInscripcionRepetida = apperrors.Conflicto("INSCRIPCION_DUPLICADA", "Ya estás inscrito en esta actividad")
+)
+
+// Transiciones de estado permitidas para un pago
+var transicionesPago = map[string][]string{
+	models.PagoPendiente:   {models.PagoAprobado, models.PagoRechazado, models.PagoCancelado},
+	models.PagoAprobado:    {models.PagoReembolsado},
+	models.PagoAReembolsar: {models.PagoReembolsado},
+}
+
+func PuedeTransicionarPago(desde, hacia string) bool {
+	for _, estado := range transicionesPago[desde] {
+		if estado == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+type CrearPagoRequest struct {
+	Concepto    string `json:"concepto" binding:"required"`
+	Plan        string `json:"plan"`
+	ActividadID uint   `json:"actividad_id"`
+}
+
+func PrecioClaseSuelta() int64 {
+	return int64(config.App.Pagos.PrecioClase)
+}
+
+func CrearPago(usuarioID uint, req CrearPagoRequest) (*models.Pago, error) {
+	if proveedorPagos == nil {
+		return nil, ErrPagosDeshabilitados
+	}
+
+	pago := models.Pago{
+		UsuarioID: usuarioID,
+		Concepto:  req.Concepto,
+		Moneda:    "ARS",
+		Estado:    models.PagoPendiente,
+		Proveedor: proveedorPagos.Nombre(),
+	}
+
+	switch req.Concepto {
+	case models.ConceptoSuscripcion:
+		plan, ok := Planes[req.Plan]
+		if !ok {
+			return nil, ErrPlanInexistente
+		}
+		pago.Plan = plan.Nombre
+		pago.Monto = plan.Monto
+	case models.ConceptoInscripcion:
+		if !EmailVerificado(usuarioID) {
+			return nil, ErrEmailNoVerificado
+		}
+		if hasta, suspendido := SuspensionVigente(usuarioID); suspendido {
+			return nil, ErrReservasSuspendidas.ConDetalle("suspension_hasta", hasta)
+		}
+		actividad, err := GetActividadByIDConCupo(req.ActividadID)
+		if err != nil {
+			return nil, err
+		}
+		if actividad.CupoDisponible <= 0 {
+			return nil, ErrActividadSinCupo
+		}
+		var existentes int64
+		config.DB.Model(&models.Inscripcion{}).
+			Where("usuario_id = ? AND actividad_id = ?", usuarioID, actividad.ID).
+			Count(&existentes)
+		if existentes > 0 {
+			return nil, ErrInscripcionRepetida
+		}
+		pago.ActividadID = &actividad.ID
+		pago.Monto = PrecioClaseSuelta()
+	default:
+		return nil, ErrConceptoInvalido
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if pago.Concepto == models.ConceptoSuscripcion {
+			suscripcion := models.Suscripcion{
+				UsuarioID: usuarioID,
+				Plan:      pago.Plan,
+				Estado:    models.SuscripcionPendiente,
+			}
+			if err := tx.Create(&suscripcion).Error; err != nil {
+				return err
+			}
+			pago.SuscripcionID = &suscripcion.ID
+		}
+
+		if err := tx.Create(&pago).Error; err != nil {
+			return err
+		}
+
+		cobro, err := proveedorPagos.CrearCobro(&pago)
+		if err != nil {
+			return fmt.Errorf("error creando cobro en %s: %w", pago.Proveedor, err)
+		}
+		pago.ReferenciaExterna = cobro.ReferenciaExterna
+		pago.URLPago = cobro.URLPago
+		return tx.Save(&pago).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pago, nil
+}
+
+// ProcesarEventoPago aplica la notificación de la pasarela al pago
+// correspondiente y, si fue aprobado, activa lo que el socio compró.
+func ProcesarEventoPago(proveedor string, evento *EventoPago) (*models.Pago, error) {
+	var pago models.Pago
+	inscripcionCreada := false
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("proveedor = ? AND referencia_externa = ?", proveedor, evento.ReferenciaExterna).
+			First(&pago).Error; err != nil {
+			return ErrPagoNoEncontrado
+		}
+
+		// Los webhooks pueden repetirse: un evento ya aplicado no es un error.
+		// Un pago a reembolsar ya procesó su aprobación.
+		if pago.Estado == evento.Estado ||
+			(pago.Estado == models.PagoAReembolsar && evento.Estado == models.PagoAprobado) {
+			return nil
+		}
+		if !PuedeTransicionarPago(pago.Estado, evento.Estado) {
+			return ErrTransicionInvalida
+		}
+
+		pago.Estado = evento.Estado
+		if evento.Estado == models.PagoAprobado {
+			now := time.Now()
+			pago.ConfirmadoAt = &now
+			if err := activarPago(tx, &pago); err != nil {
+				// Sin cupo o ya inscrito: no se entrega lo cobrado, así que el
+				// pago queda a reembolsar y no se factura. Otros errores cancelan
+				// la transacción para que la pasarela reintente el webhook.
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) {
+					return err
+				}
+				slog.Warn("pago aprobado sin poder activarse; se pide el reembolso",
+					"pago_id", pago.ID, "motivo", appErr.Codigo)
+				pago.Estado = models.PagoAReembolsar
+				pago.Detalle = appErr.Codigo
+				return tx.Save(&pago).Error
+			}
+			inscripcionCreada = pago.InscripcionID != nil
+			if _, err := EmitirFactura(tx, &pago); err != nil {
+				return err
+			}
+		}
+		if evento.Estado == models.PagoReembolsado {
+			now := time.Now()
+			if err := tx.Model(&models.Factura{}).
+				Where("pago_id = ? AND estado = ?", pago.ID, models.FacturaEmitida).
+				Updates(map[string]interface{}{
+					"estado":           models.FacturaAnulada,
+					"anulada_at":       &now,
+					"motivo_anulacion": "Pago reembolsado",
+				}).Error; err != nil {
+				return err
+			}
+		}
+		if evento.Estado == models.PagoRechazado || evento.Estado == models.PagoCancelado {
+			if pago.SuscripcionID != nil {
+				if err := tx.Model(&models.Suscripcion{}).Where("id = ?", *pago.SuscripcionID).
+					Update("estado", models.SuscripcionCancelada).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Save(&pago).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if inscripcionCreada {
+		metrics.InscripcionesCreadas.Inc()
+		PublicarCupo(*pago.ActividadID)
+	}
+
+	// El pedido se hace fuera de la transacción: si falla, el pago ya quedó
+	// a reembolsar y lo vuelve a pedir IniciarReintentoReembolsos
+	if pago.Estado == models.PagoAReembolsar && pago.ReembolsoPedidoAt == nil {
+		reembolsado, err := pedirReembolso(&pago)
+		if err != nil {
+			slog.Error("no se pudo pedir el reembolso", "pago_id", pago.ID, "error", err)
+			return &pago, nil
+		}
+		return reembolsado, nil
+	}
+	return &pago, nil
+}
+
+// pedirReembolso pide a la pasarela que devuelva un pago a reembolsar. El
+// pago sólo pasa a reembolsado cuando la pasarela confirma la devolución.
+func pedirReembolso(pago *models.Pago) (*models.Pago, error) {
+	evento, err := proveedorPagos.Reembolsar(pago)
+	if err != nil {
+		return nil, fmt.Errorf("error pidiendo el reembolso a %s: %w", pago.Proveedor, err)
+	}
+
+	ahora := time.Now()
+	pago.ReembolsoPedidoAt = &ahora
+	if err := config.DB.Model(pago).Update("reembolso_pedido_at", ahora).Error; err != nil {
+		return nil, err
+	}
+	if evento == nil {
+		return pago, nil
+	}
+	return ProcesarEventoPago(pago.Proveedor, evento)
+}
+
+// IniciarReintentoReembolsos vuelve a pedir cada hora los reembolsos que la
+// pasarela no llegó a recibir, hasta que se cancele el contexto.
+func IniciarReintentoReembolsos(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ReintentarReembolsos(); err != nil {
+				slog.Error("error reintentando reembolsos", "error", err)
+			}
+		}
+	}
+}
+
+// ReintentarReembolsos pide los reembolsos pendientes de la pasarela actual.
+// Un pedido fallido no corta el resto: se reintenta en la próxima pasada.
+func ReintentarReembolsos() error {
+	if proveedorPagos == nil {
+		return nil
+	}
+
+	var pagos []models.Pago
+	if err := config.DB.Where("estado = ? AND reembolso_pedido_at IS NULL AND proveedor = ?",
+		models.PagoAReembolsar, proveedorPagos.Nombre()).Order("id").Find(&pagos).Error; err != nil {
+		return err
+	}
+
+	for i := range pagos {
+		if _, err := pedirReembolso(&pagos[i]); err != nil {
+			slog.Warn("reembolso pendiente", "pago_id", pagos[i].ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func activarPago(tx *gorm.DB, pago *models.Pago) error {
+	switch pago.Concepto {
+	case models.ConceptoSuscripcion:
+		var suscripcion models.Suscripcion
+		if err := tx.First(&suscripcion, *pago.SuscripcionID).Error; err != nil {
+			return err
+		}
+		inicio := *pago.ConfirmadoAt
+		fin := inicio.AddDate(0, 0, Planes[suscripcion.Plan].DuracionDias)
+		suscripcion.Estado = models.SuscripcionActiva
+		suscripcion.FechaInicio = &inicio
+		suscripcion.FechaFin = &fin
+		return tx.Save(&suscripcion).Error
+
+	case models.ConceptoInscripcion:
+		var actividad models.Actividad
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&actividad, *pago.ActividadID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrActividadNoEncontrada
+			}
+			return err
+		}
+
+		var existentes int64
+		tx.Model(&models.Inscripcion{}).
+			Where("usuario_id = ? AND actividad_id = ?", pago.UsuarioID, actividad.ID).
+			Count(&existentes)
+		if existentes > 0 {
+			return ErrInscripcionRepetida
+		}
+
+		var inscripcionesCount int64
+		tx.Model(&models.Inscripcion{}).Where("actividad_id = ?", actividad.ID).Count(&inscripcionesCount)
+		if int(inscripcionesCount) >= actividad.CupoMaximo {
+			return ErrActividadSinCupo
+		}
+
+		inscripcion := models.Inscripcion{
+			UsuarioID:        pago.UsuarioID,
+			ActividadID:      actividad.ID,
+			FechaInscripcion: pago.ConfirmadoAt,
+		}
+		if err := tx.Create(&inscripcion).Error; err != nil {
+			return err
+		}
+		pago.InscripcionID = &inscripcion.ID
+
+		return EncolarNotificacion(tx, pago.UsuarioID, PlantillaInscripcionConfirmada, map[string]interface{}{
+			"Actividad": actividad,
+		})
+	}
+
+	return nil
+}
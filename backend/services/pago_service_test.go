@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestPuedeTransicionarPago(t *testing.T) {
+	casos := []struct {
+		desde, hacia string
+		permitido    bool
+	}{
+		{models.PagoPendiente, models.PagoAprobado, true},
+		{models.PagoPendiente, models.PagoRechazado, true},
+		{models.PagoPendiente, models.PagoCancelado, true},
+		{models.PagoPendiente, models.PagoReembolsado, false},
+		{models.PagoAprobado, models.PagoReembolsado, true},
+		{models.PagoAprobado, models.PagoRechazado, false},
+		{models.PagoRechazado, models.PagoAprobado, false},
+		{models.PagoReembolsado, models.PagoAprobado, false},
+	}
+	for _, caso := range casos {
+		if got := PuedeTransicionarPago(caso.desde, caso.hacia); got != caso.permitido {
+			t.Errorf("PuedeTransicionarPago(%q, %q) = %v, se esperaba %v", caso.desde, caso.hacia, got, caso.permitido)
+		}
+	}
+}
+
+func TestProveedorFakeWebhook(t *testing.T) {
+	proveedor := NewProveedorFake("secreto")
+	payload := []byte(`{"referencia":"fake_123","estado":"aprobado"}`)
+
+	casos := []struct {
+		nombre  string
+		payload []byte
+		firma   string
+		valido  bool
+	}{
+		{"firma correcta", payload, proveedor.Firmar(payload), true},
+		{"firma de otro secreto", payload, NewProveedorFake("otro").Firmar(payload), false},
+		{"payload alterado", []byte(`{"referencia":"fake_999","estado":"aprobado"}`), proveedor.Firmar(payload), false},
+		{"sin firma", payload, "", false},
+		{"json inválido", []byte(`{`), proveedor.Firmar([]byte(`{`)), false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			evento, err := proveedor.ParsearWebhook(caso.payload, caso.firma)
+			if !caso.valido {
+				if !errors.Is(err, ErrWebhookInvalido) {
+					t.Fatalf("se esperaba ErrWebhookInvalido, se obtuvo %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if evento.ReferenciaExterna != "fake_123" || evento.Estado != models.PagoAprobado {
+				t.Errorf("evento inesperado: %+v", evento)
+			}
+		})
+	}
+}
+
+// pasarelaReembolsos es la pasarela fake con la respuesta a los pedidos de
+// reembolso controlada por el test.
+type pasarelaReembolsos struct {
+	*ProveedorFake
+	err     error
+	asincro bool
+	pedidos int
+}
+
+func (p *pasarelaReembolsos) Reembolsar(pago *models.Pago) (*EventoPago, error) {
+	p.pedidos++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.asincro {
+		return nil, nil
+	}
+	return p.ProveedorFake.Reembolsar(pago)
+}
+
+func TestProcesarEventoPagoInscripcion(t *testing.T) {
+	casos := []struct {
+		nombre        string
+		cupo          int
+		pasarela      *pasarelaReembolsos
+		estado        string
+		detalle       string
+		pedido        bool
+		facturas      int64
+		inscripciones int64
+	}{
+		{"con cupo se inscribe y factura", 2, &pasarelaReembolsos{},
+			models.PagoAprobado, "", false, 1, 2},
+		{"sin cupo se reembolsa sin factura", 1, &pasarelaReembolsos{},
+			models.PagoReembolsado, ErrActividadSinCupo.Codigo, true, 0, 1},
+		{"sin cupo espera la confirmación de la pasarela", 1, &pasarelaReembolsos{asincro: true},
+			models.PagoAReembolsar, ErrActividadSinCupo.Codigo, true, 0, 1},
+		{"sin cupo y la pasarela no responde", 1, &pasarelaReembolsos{err: errors.New("timeout")},
+			models.PagoAReembolsar, ErrActividadSinCupo.Codigo, false, 0, 1},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			caso.pasarela.ProveedorFake = usarProveedorFake(t)
+			proveedorPagos = caso.pasarela
+			actividad := nuevaActividad("Yoga", "Lunes", "18:00", caso.cupo)
+			ocupante := nuevoUsuario("ocupante@gym.test")
+			socio := nuevoUsuario("socio@gym.test")
+			crear(t, actividad, ocupante, socio)
+			crear(t, &models.Inscripcion{UsuarioID: ocupante.ID, ActividadID: actividad.ID})
+			crear(t, &models.Pago{
+				UsuarioID:         socio.ID,
+				Concepto:          models.ConceptoInscripcion,
+				ActividadID:       &actividad.ID,
+				Monto:             150000,
+				Estado:            models.PagoPendiente,
+				Proveedor:         "fake",
+				ReferenciaExterna: "fake_1",
+			})
+
+			pago, err := ProcesarEventoPago("fake", &EventoPago{ReferenciaExterna: "fake_1", Estado: models.PagoAprobado})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pago.Estado != caso.estado || pago.Detalle != caso.detalle {
+				t.Errorf("pago quedó %q (%q), se esperaba %q (%q)", pago.Estado, pago.Detalle, caso.estado, caso.detalle)
+			}
+			if pedido := pago.ReembolsoPedidoAt != nil; pedido != caso.pedido {
+				t.Errorf("reembolso pedido = %v, se esperaba %v", pedido, caso.pedido)
+			}
+
+			var facturas, inscripciones int64
+			config.DB.Model(&models.Factura{}).Where("pago_id = ?", pago.ID).Count(&facturas)
+			config.DB.Model(&models.Inscripcion{}).Where("actividad_id = ?", actividad.ID).Count(&inscripciones)
+			if facturas != caso.facturas {
+				t.Errorf("facturas = %d, se esperaban %d", facturas, caso.facturas)
+			}
+			if inscripciones != caso.inscripciones {
+				t.Errorf("inscripciones = %d, se esperaban %d", inscripciones, caso.inscripciones)
+			}
+
+			// Un webhook de aprobación repetido no vuelve a activar ni reembolsar
+			if caso.estado == models.PagoReembolsado {
+				return
+			}
+			pedidos := caso.pasarela.pedidos
+			if _, err := ProcesarEventoPago("fake", &EventoPago{ReferenciaExterna: "fake_1", Estado: models.PagoAprobado}); err != nil {
+				t.Fatalf("webhook repetido: %v", err)
+			}
+			if caso.pedido && caso.pasarela.pedidos != pedidos {
+				t.Errorf("el webhook repetido pidió otro reembolso")
+			}
+		})
+	}
+}
+
+func TestReintentarReembolsos(t *testing.T) {
+	prepararDB(t)
+	pasarela := &pasarelaReembolsos{ProveedorFake: usarProveedorFake(t), err: errors.New("timeout")}
+	proveedorPagos = pasarela
+	actividad := nuevaActividad("Yoga", "Lunes", "18:00", 1)
+	ocupante := nuevoUsuario("ocupante@gym.test")
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, actividad, ocupante, socio)
+	crear(t, &models.Inscripcion{UsuarioID: ocupante.ID, ActividadID: actividad.ID})
+	crear(t, &models.Pago{
+		UsuarioID:         socio.ID,
+		Concepto:          models.ConceptoInscripcion,
+		ActividadID:       &actividad.ID,
+		Monto:             150000,
+		Estado:            models.PagoPendiente,
+		Proveedor:         "fake",
+		ReferenciaExterna: "fake_1",
+	})
+	if _, err := ProcesarEventoPago("fake", &EventoPago{ReferenciaExterna: "fake_1", Estado: models.PagoAprobado}); err != nil {
+		t.Fatal(err)
+	}
+
+	estado := func() string {
+		var pago models.Pago
+		config.DB.Where("referencia_externa = ?", "fake_1").First(&pago)
+		return pago.Estado
+	}
+
+	// Mientras la pasarela falle, el pago sigue a reembolsar
+	if err := ReintentarReembolsos(); err != nil {
+		t.Fatal(err)
+	}
+	if got := estado(); got != models.PagoAReembolsar {
+		t.Fatalf("con la pasarela caída el pago quedó %q", got)
+	}
+
+	pasarela.err = nil
+	if err := ReintentarReembolsos(); err != nil {
+		t.Fatal(err)
+	}
+	if got := estado(); got != models.PagoReembolsado {
+		t.Fatalf("tras el reintento el pago quedó %q, se esperaba %q", got, models.PagoReembolsado)
+	}
+
+	pedidos := pasarela.pedidos
+	if err := ReintentarReembolsos(); err != nil {
+		t.Fatal(err)
+	}
+	if pasarela.pedidos != pedidos {
+		t.Error("se volvió a pedir un reembolso ya confirmado")
+	}
+}
+
+// usarProveedorFake instala la pasarela fake mientras dure el test.
+func usarProveedorFake(t *testing.T) *ProveedorFake {
+	t.Helper()
+	anterior := proveedorPagos
+	fake := NewProveedorFake("secreto")
+	proveedorPagos = fake
+	t.Cleanup(func() { proveedorPagos = anterior })
+	return fake
+}
+
+func TestCrearPago(t *testing.T) {
+	casos := []struct {
+		nombre string
+		req    func(actividadID uint) CrearPagoRequest
+		err    error
+	}{
+		{"suscripción", func(uint) CrearPagoRequest {
+			return CrearPagoRequest{Concepto: models.ConceptoSuscripcion, Plan: "mensual"}
+		}, nil},
+		{"plan inexistente", func(uint) CrearPagoRequest {
+			return CrearPagoRequest{Concepto: models.ConceptoSuscripcion, Plan: "semanal"}
+		}, ErrPlanInexistente},
+		{"inscripción", func(actividadID uint) CrearPagoRequest {
+			return CrearPagoRequest{Concepto: models.ConceptoInscripcion, ActividadID: actividadID}
+		}, nil},
+		{"actividad inexistente", func(actividadID uint) CrearPagoRequest {
+			return CrearPagoRequest{Concepto: models.ConceptoInscripcion, ActividadID: actividadID + 100}
+		}, ErrActividadNoEncontrada},
+		{"concepto inválido", func(uint) CrearPagoRequest {
+			return CrearPagoRequest{Concepto: "donacion"}
+		}, ErrConceptoInvalido},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			usarProveedorFake(t)
+			actividad := nuevaActividad("Yoga", "Lunes", "18:00", 10)
+			socio := nuevoUsuario("socio@gym.test")
+			crear(t, actividad, socio)
+
+			pago, err := CrearPago(socio.ID, caso.req(actividad.ID))
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("err = %v, se esperaba %v", err, caso.err)
+			}
+			if err != nil {
+				return
+			}
+			if pago.Estado != models.PagoPendiente || pago.ReferenciaExterna == "" || pago.URLPago == "" {
+				t.Errorf("pago inesperado: estado %q, referencia %q, url %q", pago.Estado, pago.ReferenciaExterna, pago.URLPago)
+			}
+			// El checkout no debe llevar al alias /api, que está deprecado
+			if want := "/api/v1/pagos/fake/checkout/" + pago.ReferenciaExterna; pago.URLPago != want {
+				t.Errorf("url_pago = %q, se esperaba %q", pago.URLPago, want)
+			}
+		})
+	}
+}
+
+func TestInitPagosProd(t *testing.T) {
+	anterior, proveedorAnterior := config.App, proveedorPagos
+	t.Cleanup(func() { config.App, proveedorPagos = anterior, proveedorAnterior })
+
+	// Producción arranca sin pasarela: la API funciona y los pagos se rechazan
+	cfg := config.Defaults(config.PerfilProd)
+	cfg.JWT.Secreto = strings.Repeat("s", 40)
+	cfg.DB.Password = "clave"
+	cfg.Notificaciones.SMTP.Host = "smtp.gym.test"
+	if errs := cfg.Validar(); len(errs) > 0 {
+		t.Fatalf("la configuración de producción no es válida:\n%v", errors.Join(errs...))
+	}
+	config.App = cfg
+	if err := InitPagos(); err != nil {
+		t.Fatalf("InitPagos: %v", err)
+	}
+
+	if _, err := GetProveedorPagos(); !errors.Is(err, ErrPagosDeshabilitados) {
+		t.Errorf("GetProveedorPagos: err = %v, se esperaba %v", err, ErrPagosDeshabilitados)
+	}
+	if _, err := CrearPago(1, CrearPagoRequest{Concepto: models.ConceptoSuscripcion, Plan: "mensual"}); !errors.Is(err, ErrPagosDeshabilitados) {
+		t.Errorf("CrearPago: err = %v, se esperaba %v", err, ErrPagosDeshabilitados)
+	}
+	if err := ReintentarReembolsos(); err != nil {
+		t.Errorf("ReintentarReembolsos: %v", err)
+	}
+
+	cfg.Pagos.Proveedor = "desconocido"
+	cfg.Pagos.WebhookSecreto = "secreto"
+	if err := InitPagos(); err == nil {
+		t.Error("InitPagos aceptó un proveedor desconocido")
+	}
+}
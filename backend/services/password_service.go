@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/metrics"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTokenRecuperacionInvalido = apperrors.Invalido("TOKEN_RECUPERACION_INVALIDO", "El enlace para restablecer la contraseña es inválido o ya venció")
+	ErrPasswordActualIncorrecta  = apperrors.Invalido("PASSWORD_ACTUAL_INCORRECTA", "La contraseña actual es incorrecta")
+)
+
+// passwordCorrecta compara con el hash guardado (primero SHA256, luego MD5
+// como fallback para las cuentas antiguas).
+func passwordCorrecta(usuario *models.Usuario, password string) bool {
+	return usuario.PasswordHash == HashPasswordSHA256(password) || usuario.PasswordHash == HashPasswordMD5(password)
+}
+
+// SolicitarRecuperacion envía por correo un enlace para restablecer la
+// contraseña. Si el email no existe no hace nada y tampoco devuelve error,
+// para no revelar qué cuentas están registradas.
+func SolicitarRecuperacion(email, ip string) error {
+	var usuario models.Usuario
+	if err := config.DB.Where("email = ?", email).First(&usuario).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	minutos := config.App.Cuentas.RecuperacionMinutos
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		token, err := emitirTokenUsuario(tx, usuario.ID, models.TokenRecuperacion, time.Duration(minutos)*time.Minute)
+		if err != nil {
+			return err
+		}
+		return EncolarNotificacion(tx, usuario.ID, PlantillaRecuperarPassword, map[string]interface{}{
+			"Enlace":  enlaceCuenta("/restablecer-password", token),
+			"Minutos": minutos,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	RegistrarAuditoria(models.AuditoriaRecuperacion, &usuario.ID, nil, ip, nil)
+	return nil
+}
+
+// RestablecerPassword consume el token del enlace y guarda la nueva
+// contraseña. Cierra todas las sesiones abiertas y quita el bloqueo por
+// intentos fallidos: quien recibió el correo es el dueño de la cuenta.
+func RestablecerPassword(token, password, ip string) error {
+	var usuarioID uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		registro, ok, err := consumirTokenUsuario(tx, token, models.TokenRecuperacion)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTokenRecuperacionInvalido
+		}
+		usuarioID = registro.UsuarioID
+
+		resultado := tx.Model(&models.Usuario{}).Where("id = ?", usuarioID).Updates(map[string]interface{}{
+			"password_hash":     HashPasswordSHA256(password),
+			"version_sesion":    gorm.Expr("version_sesion + 1"),
+			"intentos_fallidos": 0,
+			"bloqueado_hasta":   nil,
+		})
+		if resultado.Error != nil {
+			return resultado.Error
+		}
+		if resultado.RowsAffected == 0 {
+			return ErrTokenRecuperacionInvalido // la cuenta se eliminó después de pedir el enlace
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	RegistrarAuditoria(models.AuditoriaPasswordReseteada, &usuarioID, nil, ip, nil)
+	return nil
+}
+
+// CambiarPassword reemplaza la contraseña de un usuario con sesión iniciada.
+// Invalida los demás tokens JWT y los enlaces de recuperación pendientes;
+// devuelve el usuario actualizado para emitir un token nuevo. Una contraseña
+// actual incorrecta cuenta como login fallido, para que un token robado no
+// permita probar contraseñas sin límite.
+func CambiarPassword(usuarioID uint, actual, nueva, ip string) (*models.Usuario, error) {
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, usuarioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+
+	ahora := time.Now()
+	if usuario.BloqueadoHasta != nil && usuario.BloqueadoHasta.After(ahora) {
+		return nil, ErrCuentaBloqueada.ConDetalle("bloqueada_hasta", *usuario.BloqueadoHasta)
+	}
+
+	if !passwordCorrecta(&usuario, actual) {
+		metrics.LoginsFallidos.Inc()
+		if err := registrarLoginFallido(&usuario, ip, ahora); !errors.Is(err, ErrCredencialesInvalidas) {
+			return nil, err
+		}
+		return nil, ErrPasswordActualIncorrecta
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&usuario).Updates(map[string]interface{}{
+			"password_hash":     HashPasswordSHA256(nueva),
+			"version_sesion":    gorm.Expr("version_sesion + 1"),
+			"intentos_fallidos": 0,
+			"bloqueado_hasta":   nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("usuario_id = ? AND tipo = ? AND usado_at IS NULL", usuario.ID, models.TokenRecuperacion).
+			Delete(&models.TokenUsuario{}).Error; err != nil {
+			return err
+		}
+		return tx.First(&usuario, usuario.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	RegistrarAuditoria(models.AuditoriaPasswordCambiada, &usuario.ID, &usuario.ID, ip, nil)
+	return &usuario, nil
+}
+
+// enlaceCuenta arma el enlace a una página del frontend con el token.
+func enlaceCuenta(ruta, token string) string {
+	return strings.TrimSuffix(config.App.Cuentas.URLFrontend, "/") + ruta + "?token=" + url.QueryEscape(token)
+}
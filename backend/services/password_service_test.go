@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+// tokenDelCorreo devuelve el token del último correo de la plantilla enviado
+// al usuario, comprobando que el enlace apunte a la página esperada.
+func tokenDelCorreo(t *testing.T, usuarioID uint, plantilla, ruta string) string {
+	t.Helper()
+	var notificacion models.Notificacion
+	if err := config.DB.Where("usuario_id = ? AND plantilla = ?", usuarioID, plantilla).
+		Order("id DESC").First(&notificacion).Error; err != nil {
+		t.Fatalf("no se encoló el correo %s: %v", plantilla, err)
+	}
+
+	prefijo := strings.TrimSuffix(config.App.Cuentas.URLFrontend, "/") + ruta + "?"
+	inicio := strings.Index(notificacion.CuerpoTexto, prefijo)
+	if inicio < 0 {
+		t.Fatalf("el correo no enlaza a %s:\n%s", prefijo, notificacion.CuerpoTexto)
+	}
+	enlace := strings.Fields(notificacion.CuerpoTexto[inicio:])[0]
+	u, err := url.Parse(enlace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := u.Query().Get("token")
+	if token == "" {
+		t.Fatalf("el enlace %q no trae token", enlace)
+	}
+	return token
+}
+
+func TestSolicitarRecuperacion(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+
+	if err := SolicitarRecuperacion("nadie@gym.test", "10.0.0.1"); err != nil {
+		t.Fatalf("email desconocido: err = %v, se esperaba nil", err)
+	}
+	var total int64
+	config.DB.Model(&models.Notificacion{}).Count(&total)
+	if total != 0 {
+		t.Fatalf("con un email desconocido se encolaron %d correos", total)
+	}
+
+	if err := SolicitarRecuperacion(socio.Email, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	primero := tokenDelCorreo(t, socio.ID, PlantillaRecuperarPassword, "/restablecer-password")
+	if err := SolicitarRecuperacion(socio.Email, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	segundo := tokenDelCorreo(t, socio.ID, PlantillaRecuperarPassword, "/restablecer-password")
+
+	// Sólo vale el último enlace enviado
+	if err := RestablecerPassword(primero, "nueva123", "10.0.0.1"); !errors.Is(err, ErrTokenRecuperacionInvalido) {
+		t.Fatalf("enlace anterior: err = %v, se esperaba %v", err, ErrTokenRecuperacionInvalido)
+	}
+	if err := RestablecerPassword(segundo, "nueva123", "10.0.0.1"); err != nil {
+		t.Fatalf("último enlace: %v", err)
+	}
+}
+
+func TestRestablecerPassword(t *testing.T) {
+	casos := []struct {
+		nombre string
+		token  func(t *testing.T, usuarioID uint) string
+		valido bool
+	}{
+		{"vigente", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenRecuperacion, time.Hour)
+		}, true},
+		{"vencido", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenRecuperacion, -time.Minute)
+		}, false},
+		{"de verificación", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenVerificacion, time.Hour)
+		}, false},
+		{"inexistente", func(t *testing.T, usuarioID uint) string {
+			return "no-existe"
+		}, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			socio := nuevoUsuario("socio@gym.test")
+			socio.IntentosFallidos = 7
+			bloqueo := time.Now().Add(time.Hour)
+			socio.BloqueadoHasta = &bloqueo
+			crear(t, socio)
+			token := caso.token(t, socio.ID)
+
+			err := RestablecerPassword(token, "nueva123", "10.0.0.1")
+			var usuario models.Usuario
+			config.DB.First(&usuario, socio.ID)
+
+			if !caso.valido {
+				if !errors.Is(err, ErrTokenRecuperacionInvalido) {
+					t.Fatalf("err = %v, se esperaba %v", err, ErrTokenRecuperacionInvalido)
+				}
+				if usuario.PasswordHash != socio.PasswordHash {
+					t.Fatal("se cambió la contraseña con un token inválido")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !passwordCorrecta(&usuario, "nueva123") {
+				t.Error("no se guardó la contraseña nueva")
+			}
+			if usuario.VersionSesion != socio.VersionSesion+1 {
+				t.Errorf("version_sesion = %d, se esperaba %d", usuario.VersionSesion, socio.VersionSesion+1)
+			}
+			if usuario.IntentosFallidos != 0 || usuario.BloqueadoHasta != nil {
+				t.Errorf("quedó el bloqueo: intentos = %d, hasta = %v", usuario.IntentosFallidos, usuario.BloqueadoHasta)
+			}
+			if err := RestablecerPassword(token, "otra123", "10.0.0.1"); !errors.Is(err, ErrTokenRecuperacionInvalido) {
+				t.Fatalf("segundo uso: err = %v, se esperaba %v", err, ErrTokenRecuperacionInvalido)
+			}
+		})
+	}
+}
+
+// Una contraseña actual incorrecta cuenta como login fallido: al llegar al
+// umbral la cuenta se bloquea y ni la contraseña correcta permite cambiarla.
+func TestCambiarPasswordBloqueo(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+	limites := config.App.Limites
+
+	for i := 1; i < limites.BloqueoIntentos; i++ {
+		if _, err := CambiarPassword(socio.ID, "incorrecta", "nueva123", "10.0.0.1"); !errors.Is(err, ErrPasswordActualIncorrecta) {
+			t.Fatalf("intento %d: err = %v, se esperaba %v", i, err, ErrPasswordActualIncorrecta)
+		}
+	}
+	if _, err := CambiarPassword(socio.ID, "incorrecta", "nueva123", "10.0.0.1"); !errors.Is(err, ErrCuentaBloqueada) {
+		t.Fatalf("al llegar al umbral: err = %v, se esperaba %v", err, ErrCuentaBloqueada)
+	}
+	if _, err := CambiarPassword(socio.ID, "secreto", "nueva123", "10.0.0.1"); !errors.Is(err, ErrCuentaBloqueada) {
+		t.Fatalf("cuenta bloqueada: err = %v, se esperaba %v", err, ErrCuentaBloqueada)
+	}
+	if _, err := IniciarSesion(socio.Email, "secreto", "10.0.0.1"); !errors.Is(err, ErrCuentaBloqueada) {
+		t.Fatalf("login con la cuenta bloqueada: err = %v, se esperaba %v", err, ErrCuentaBloqueada)
+	}
+
+	config.DB.Model(&models.Usuario{}).Where("id = ?", socio.ID).
+		Update("bloqueado_hasta", time.Now().Add(-time.Second))
+	usuario, err := CambiarPassword(socio.ID, "secreto", "nueva123", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !passwordCorrecta(usuario, "nueva123") {
+		t.Error("no se guardó la contraseña nueva")
+	}
+	if usuario.IntentosFallidos != 0 || usuario.BloqueadoHasta != nil {
+		t.Errorf("el cambio no reinició el contador: %d intentos, bloqueada hasta %v",
+			usuario.IntentosFallidos, usuario.BloqueadoHasta)
+	}
+}
+
+func emitir(t *testing.T, usuarioID uint, tipo string, vigencia time.Duration) string {
+	t.Helper()
+	token, err := emitirTokenUsuario(config.DB, usuarioID, tipo, vigencia)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Canales por los que se pueden enviar recordatorios
+var CanalesRecordatorio = []string{"email"}
+
+var ErrCanalInvalido = apperrors.Invalido("CANAL_INVALIDO", "Canal de notificación inválido")
+
+// IniciarSchedulerRecordatorios revisa periódicamente las próximas sesiones y
+// encola un recordatorio para cada socio inscrito, hasta que se cancele el contexto.
+func IniciarSchedulerRecordatorios(ctx context.Context) {
+	intervalo := time.Duration(config.App.Recordatorios.IntervaloSegundos) * time.Second
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ProgramarRecordatorios(time.Now()); err != nil {
+				slog.Error("error programando recordatorios", "error", err)
+			}
+		}
+	}
+}
+
+// ProgramarRecordatorios encola los recordatorios de las sesiones que empiezan
+// dentro de la anticipación configurada. Cada envío queda registrado en
+// Recordatorio, así que ejecutar esto varias veces no duplica mensajes.
+func ProgramarRecordatorios(ahora time.Time) error {
+	anticipacion := time.Duration(config.App.Recordatorios.AnticipacionMinutos) * time.Minute
+	hasta := ahora.Add(anticipacion)
+
+	// Sólo interesan las actividades cuya próxima sesión cae en la ventana
+	var actividades []models.Actividad
+	if err := config.DB.Where("dia IN ?", diasEntre(ahora, hasta)).Find(&actividades).Error; err != nil {
+		return err
+	}
+	sesiones := make(map[uint]time.Time)
+	actividadesPorID := make(map[uint]models.Actividad)
+	for _, actividad := range actividades {
+		sesion, ok := ProximaSesion(&actividad, ahora)
+		if !ok || sesion.After(hasta) {
+			continue
+		}
+		sesiones[actividad.ID] = sesion
+		actividadesPorID[actividad.ID] = actividad
+	}
+	if len(sesiones) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(sesiones))
+	for id := range sesiones {
+		ids = append(ids, id)
+	}
+	var inscripciones []models.Inscripcion
+	if err := config.DB.Where("actividad_id IN ?", ids).Find(&inscripciones).Error; err != nil {
+		return err
+	}
+	if len(inscripciones) == 0 {
+		return nil
+	}
+
+	usuarios := make([]uint, 0, len(inscripciones))
+	for _, inscripcion := range inscripciones {
+		usuarios = append(usuarios, inscripcion.UsuarioID)
+	}
+	deshabilitados, err := recordatoriosDeshabilitados(usuarios)
+	if err != nil {
+		return err
+	}
+
+	for _, inscripcion := range inscripciones {
+		inscripcion.Actividad = actividadesPorID[inscripcion.ActividadID]
+		sesion := sesiones[inscripcion.ActividadID]
+
+		for _, canal := range CanalesRecordatorio {
+			if deshabilitados[preferenciaClave{inscripcion.UsuarioID, canal}] {
+				continue
+			}
+			if err := enviarRecordatorio(&inscripcion, sesion, canal); err != nil {
+				slog.Warn("error encolando recordatorio", "inscripcion_id", inscripcion.ID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// diasEntre devuelve los nombres de los días de la semana que abarca el rango.
+func diasEntre(desde, hasta time.Time) []string {
+	incluidos := make(map[time.Weekday]bool)
+	inicio := time.Date(desde.Year(), desde.Month(), desde.Day(), 0, 0, 0, 0, desde.Location())
+	for dia := inicio; !dia.After(hasta) && len(incluidos) < 7; dia = dia.AddDate(0, 0, 1) {
+		incluidos[dia.Weekday()] = true
+	}
+
+	dias := make([]string, 0, len(incluidos))
+	for nombre, dia := range diasSemana {
+		if incluidos[dia] {
+			dias = append(dias, nombre)
+		}
+	}
+	return dias
+}
+
+type preferenciaClave struct {
+	usuarioID uint
+	canal     string
+}
+
+// recordatoriosDeshabilitados carga en una sola consulta qué usuarios
+// desactivaron los recordatorios y en qué canal.
+func recordatoriosDeshabilitados(usuarios []uint) (map[preferenciaClave]bool, error) {
+	var preferencias []models.PreferenciaNotificacion
+	if err := config.DB.Where("usuario_id IN ? AND recordatorios = ?", usuarios, false).
+		Find(&preferencias).Error; err != nil {
+		return nil, err
+	}
+
+	deshabilitados := make(map[preferenciaClave]bool, len(preferencias))
+	for _, preferencia := range preferencias {
+		deshabilitados[preferenciaClave{preferencia.UsuarioID, preferencia.Canal}] = true
+	}
+	return deshabilitados, nil
+}
+
+func enviarRecordatorio(inscripcion *models.Inscripcion, sesion time.Time, canal string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		recordatorio := models.Recordatorio{
+			InscripcionID: inscripcion.ID,
+			Sesion:        sesion,
+			Canal:         canal,
+			UsuarioID:     inscripcion.UsuarioID,
+			ActividadID:   inscripcion.ActividadID,
+		}
+
+		resultado := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&recordatorio)
+		if resultado.Error != nil {
+			return resultado.Error
+		}
+		if resultado.RowsAffected == 0 {
+			// Ya se envió el recordatorio de esta sesión
+			return nil
+		}
+
+		return EncolarNotificacion(tx, inscripcion.UsuarioID, PlantillaRecordatorioClase, map[string]interface{}{
+			"Actividad": inscripcion.Actividad,
+			"Sesion":    sesion,
+		})
+	})
+}
+
+func RecordatoriosHabilitados(usuarioID uint, canal string) bool {
+	var preferencia models.PreferenciaNotificacion
+	if err := config.DB.Where("usuario_id = ? AND canal = ?", usuarioID, canal).First(&preferencia).Error; err != nil {
+		// Sin preferencia guardada los recordatorios están activos
+		return true
+	}
+	return preferencia.Recordatorios
+}
+
+func GetPreferenciasNotificacion(usuarioID uint) []models.PreferenciaNotificacion {
+	preferencias := make([]models.PreferenciaNotificacion, 0, len(CanalesRecordatorio))
+	for _, canal := range CanalesRecordatorio {
+		preferencias = append(preferencias, models.PreferenciaNotificacion{
+			UsuarioID:     usuarioID,
+			Canal:         canal,
+			Recordatorios: RecordatoriosHabilitados(usuarioID, canal),
+		})
+	}
+	return preferencias
+}
+
+func EsCanalRecordatorio(canal string) bool {
+	for _, c := range CanalesRecordatorio {
+		if c == canal {
+			return true
+		}
+	}
+	return false
+}
+
+func GuardarPreferenciaNotificacion(usuarioID uint, canal string, recordatorios bool) error {
+	preferencia := models.PreferenciaNotificacion{
+		UsuarioID:     usuarioID,
+		Canal:         canal,
+		Recordatorios: recordatorios,
+	}
+	return config.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"recordatorios", "updated_at"}),
+	}).Create(&preferencia).Error
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestDiasEntre(t *testing.T) {
+	// Miércoles 15 de mayo de 2024
+	miercoles := time.Date(2024, time.May, 15, 22, 0, 0, 0, time.UTC)
+
+	casos := []struct {
+		nombre   string
+		hasta    time.Time
+		esperado []string
+	}{
+		{"mismo día", miercoles.Add(time.Hour), []string{"Miércoles"}},
+		{"cruza la medianoche", miercoles.Add(3 * time.Hour), []string{"Jueves", "Miércoles"}},
+		{"tres días", miercoles.AddDate(0, 0, 2), []string{"Jueves", "Miércoles", "Viernes"}},
+		{"más de una semana", miercoles.AddDate(0, 0, 10),
+			[]string{"Domingo", "Jueves", "Lunes", "Martes", "Miércoles", "Sábado", "Viernes"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			dias := diasEntre(miercoles, caso.hasta)
+			sort.Strings(dias)
+			if strings.Join(dias, ",") != strings.Join(caso.esperado, ",") {
+				t.Errorf("diasEntre = %v, se esperaba %v", dias, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestProgramarRecordatorios(t *testing.T) {
+	prepararDB(t)
+	// Miércoles 15 de mayo de 2024, 16:30; la anticipación por defecto es de dos horas
+	ahora := time.Date(2024, time.May, 15, 16, 30, 0, 0, time.Local)
+
+	enVentana := nuevaActividad("Yoga", "Miércoles", "18:00", 10)
+	fueraDeVentana := nuevaActividad("Pilates", "Miércoles", "19:00", 10)
+	otroDia := nuevaActividad("Boxeo", "Jueves", "17:00", 10)
+	avisado := nuevoUsuario("avisado@gym.test")
+	sinAvisos := nuevoUsuario("sin-avisos@gym.test")
+	crear(t, enVentana, fueraDeVentana, otroDia, avisado, sinAvisos)
+	crear(t,
+		&models.Inscripcion{UsuarioID: avisado.ID, ActividadID: enVentana.ID},
+		&models.Inscripcion{UsuarioID: sinAvisos.ID, ActividadID: enVentana.ID},
+		&models.Inscripcion{UsuarioID: avisado.ID, ActividadID: fueraDeVentana.ID},
+		&models.Inscripcion{UsuarioID: avisado.ID, ActividadID: otroDia.ID},
+	)
+	if err := GuardarPreferenciaNotificacion(sinAvisos.ID, "email", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Correr el scheduler varias veces no repite los avisos
+	for i := 0; i < 3; i++ {
+		if err := ProgramarRecordatorios(ahora); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var recordatorios []models.Recordatorio
+	config.DB.Find(&recordatorios)
+	if len(recordatorios) != 1 {
+		t.Fatalf("recordatorios = %d, se esperaba 1", len(recordatorios))
+	}
+	if recordatorios[0].UsuarioID != avisado.ID || recordatorios[0].ActividadID != enVentana.ID {
+		t.Errorf("recordatorio para usuario %d y actividad %d, se esperaba %d y %d",
+			recordatorios[0].UsuarioID, recordatorios[0].ActividadID, avisado.ID, enVentana.ID)
+	}
+
+	var notificaciones int64
+	config.DB.Model(&models.Notificacion{}).Where("plantilla = ?", PlantillaRecordatorioClase).Count(&notificaciones)
+	if notificaciones != 1 {
+		t.Errorf("notificaciones = %d, se esperaba 1", notificaciones)
+	}
+}
+
+func TestGuardarPreferenciaNotificacion(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	crear(t, socio)
+
+	if !RecordatoriosHabilitados(socio.ID, "email") {
+		t.Fatal("sin preferencia guardada los recordatorios deberían estar activos")
+	}
+	for _, habilitados := range []bool{false, true, false} {
+		if err := GuardarPreferenciaNotificacion(socio.ID, "email", habilitados); err != nil {
+			t.Fatal(err)
+		}
+		if got := RecordatoriosHabilitados(socio.ID, "email"); got != habilitados {
+			t.Errorf("RecordatoriosHabilitados = %v tras guardar %v", got, habilitados)
+		}
+	}
+}
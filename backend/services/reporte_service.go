@@ -0,0 +1,261 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRangoFechasInvalido = apperrors.Invalido("RANGO_FECHAS_INVALIDO", "Rango de fechas inválido")
+	ErrAgrupacionInvalida  = apperrors.Invalido("AGRUPACION_INVALIDA", "Agrupación de reporte inválida")
+)
+
+// Agrupaciones del reporte de ocupación: columna de actividades por la que se
+// agrupa y columna que se muestra como nombre del grupo.
+var agrupacionesOcupacion = map[string][2]string{
+	"actividad": {"id", "titulo"},
+	"categoria": {"categoria", "categoria"},
+	"profesor":  {"profesor", "profesor"},
+	"dia":       {"dia", "dia"},
+}
+
+// idActividad es la clave de la tabla de actividades en las consultas que
+// parten de models.Actividad, para unirla con las subconsultas por actividad_id.
+var idActividad = clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}
+
+// Períodos del reporte de tendencia, como formato de DATE_FORMAT de MySQL
+var periodosTendencia = map[string]string{
+	"dia":    "%Y-%m-%d",
+	"semana": "%x-W%v",
+	"mes":    "%Y-%m",
+}
+
+// RangoReporte es el intervalo de fechas de un reporte, con Hasta exclusivo.
+type RangoReporte struct {
+	Desde time.Time
+	Hasta time.Time
+}
+
+// NuevoRangoReporte interpreta las fechas AAAA-MM-DD de un reporte. Sin
+// fechas se toman los últimos 30 días; hasta incluye el día indicado.
+func NuevoRangoReporte(desde, hasta string, ahora time.Time) (RangoReporte, error) {
+	hoy := time.Date(ahora.Year(), ahora.Month(), ahora.Day(), 0, 0, 0, 0, ahora.Location())
+	rango := RangoReporte{Desde: hoy.AddDate(0, 0, -29), Hasta: hoy.AddDate(0, 0, 1)}
+
+	if desde != "" {
+		d, err := time.ParseInLocation(config.FormatoFecha, desde, ahora.Location())
+		if err != nil {
+			return rango, ErrRangoFechasInvalido.ConDetalle("desde", desde)
+		}
+		rango.Desde = d
+	}
+	if hasta != "" {
+		h, err := time.ParseInLocation(config.FormatoFecha, hasta, ahora.Location())
+		if err != nil {
+			return rango, ErrRangoFechasInvalido.ConDetalle("hasta", hasta)
+		}
+		rango.Hasta = h.AddDate(0, 0, 1)
+	}
+
+	if !rango.Desde.Before(rango.Hasta) {
+		return rango, ErrRangoFechasInvalido
+	}
+	return rango, nil
+}
+
+type FilaOcupacion struct {
+	Grupo         string  `json:"grupo"`
+	Actividades   int     `json:"actividades"`
+	CupoTotal     int     `json:"cupo_total"`
+	Inscripciones int     `json:"inscripciones"`
+	Ocupacion     float64 `json:"ocupacion"` // porcentaje
+}
+
+type FilaTendencia struct {
+	Periodo       string `json:"periodo"`
+	Inscripciones int    `json:"inscripciones"`
+	Cancelaciones int    `json:"cancelaciones"`
+}
+
+type FilaCancelaciones struct {
+	ActividadID     uint    `json:"actividad_id"`
+	Actividad       string  `json:"actividad"`
+	Inscripciones   int     `json:"inscripciones"`
+	Cancelaciones   int     `json:"cancelaciones"`
+	Tardias         int     `json:"tardias"`
+	Ausencias       int     `json:"ausencias"`
+	TasaCancelacion float64 `json:"tasa_cancelacion"` // porcentaje
+}
+
+type FilaFranja struct {
+	Dia           string  `json:"dia"`
+	Horario       string  `json:"horario"`
+	Actividades   int     `json:"actividades"`
+	CupoTotal     int     `json:"cupo_total"`
+	Inscripciones int     `json:"inscripciones"`
+	Ocupacion     float64 `json:"ocupacion"` // porcentaje
+}
+
+// inscripcionesVigentes cuenta por actividad las inscripciones que estuvieron
+// activas en algún momento del rango. Incluye las dadas de baja después de Desde.
+func inscripcionesVigentes(rango RangoReporte) *gorm.DB {
+	return config.DB.Unscoped().Model(&models.Inscripcion{}).
+		Select("actividad_id, COUNT(*) AS total").
+		Where("fecha_inscripcion < ? AND (deleted_at IS NULL OR deleted_at >= ?)", rango.Hasta, rango.Desde).
+		Group("actividad_id")
+}
+
+// ReporteOcupacion calcula la ocupación (inscritos sobre cupo) agrupada por
+// actividad, categoría, profesor o día de la semana.
+func ReporteOcupacion(agrupacion string, rango RangoReporte) ([]FilaOcupacion, error) {
+	columnas, ok := agrupacionesOcupacion[agrupacion]
+	if !ok {
+		return nil, ErrAgrupacionInvalida.ConDetalle("agrupar", agrupacion)
+	}
+
+	var filas []FilaOcupacion
+	err := config.DB.Model(&models.Actividad{}).
+		Select(columnas[1]+` AS grupo,
+			COUNT(*) AS actividades,
+			SUM(cupo_maximo) AS cupo_total,
+			COALESCE(SUM(i.total), 0) AS inscripciones`).
+		Joins("LEFT JOIN (?) i ON i.actividad_id = ?", inscripcionesVigentes(rango), idActividad).
+		Group(columnas[0] + ", " + columnas[1]).
+		Order("grupo").
+		Scan(&filas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range filas {
+		filas[i].Ocupacion = porcentaje(filas[i].Inscripciones, filas[i].CupoTotal)
+	}
+	return filas, nil
+}
+
+// ReporteTendencia cuenta las inscripciones y bajas de cada día, semana o mes del rango.
+func ReporteTendencia(periodo string, rango RangoReporte) ([]FilaTendencia, error) {
+	formato, ok := periodosTendencia[periodo]
+	if !ok {
+		return nil, ErrAgrupacionInvalida.ConDetalle("periodo", periodo)
+	}
+
+	var altas, bajas []struct {
+		Periodo string
+		Total   int
+	}
+	// Unscoped: las altas del rango cuentan aunque después se hayan dado de baja
+	if err := config.DB.Unscoped().Model(&models.Inscripcion{}).
+		Select("DATE_FORMAT(fecha_inscripcion, ?) AS periodo, COUNT(*) AS total", formato).
+		Where("fecha_inscripcion >= ? AND fecha_inscripcion < ?", rango.Desde, rango.Hasta).
+		Group("periodo").Scan(&altas).Error; err != nil {
+		return nil, err
+	}
+	if err := config.DB.Unscoped().Model(&models.Inscripcion{}).
+		Select("DATE_FORMAT(deleted_at, ?) AS periodo, COUNT(*) AS total", formato).
+		Where("deleted_at >= ? AND deleted_at < ?", rango.Desde, rango.Hasta).
+		Group("periodo").Scan(&bajas).Error; err != nil {
+		return nil, err
+	}
+
+	porPeriodo := map[string]*FilaTendencia{}
+	fila := func(periodo string) *FilaTendencia {
+		if porPeriodo[periodo] == nil {
+			porPeriodo[periodo] = &FilaTendencia{Periodo: periodo}
+		}
+		return porPeriodo[periodo]
+	}
+	for _, a := range altas {
+		fila(a.Periodo).Inscripciones = a.Total
+	}
+	for _, b := range bajas {
+		fila(b.Periodo).Cancelaciones = b.Total
+	}
+
+	filas := make([]FilaTendencia, 0, len(porPeriodo))
+	for _, f := range porPeriodo {
+		filas = append(filas, *f)
+	}
+	sort.Slice(filas, func(i, j int) bool { return filas[i].Periodo < filas[j].Periodo })
+	return filas, nil
+}
+
+// ReporteCancelaciones calcula por actividad qué parte de las inscripciones
+// del rango se dieron de baja, y cuántas faltas tardías y ausencias hubo.
+func ReporteCancelaciones(rango RangoReporte) ([]FilaCancelaciones, error) {
+	inscripciones := config.DB.Unscoped().Model(&models.Inscripcion{}).
+		Select("actividad_id, COUNT(*) AS inscripciones, SUM(deleted_at IS NOT NULL) AS cancelaciones").
+		Where("fecha_inscripcion >= ? AND fecha_inscripcion < ?", rango.Desde, rango.Hasta).
+		Group("actividad_id")
+	penalizaciones := config.DB.Model(&models.Penalizacion{}).
+		Select("actividad_id, SUM(tipo = ?) AS tardias, SUM(tipo = ?) AS ausencias",
+			models.PenalizacionCancelacionTardia, models.PenalizacionAusencia).
+		Where("created_at >= ? AND created_at < ?", rango.Desde, rango.Hasta).
+		Group("actividad_id")
+
+	var filas []FilaCancelaciones
+	err := config.DB.Model(&models.Actividad{}).
+		Select(`? AS actividad_id, titulo AS actividad,
+			COALESCE(i.inscripciones, 0) AS inscripciones,
+			COALESCE(i.cancelaciones, 0) AS cancelaciones,
+			COALESCE(p.tardias, 0) AS tardias,
+			COALESCE(p.ausencias, 0) AS ausencias`, idActividad).
+		Joins("LEFT JOIN (?) i ON i.actividad_id = ?", inscripciones, idActividad).
+		Joins("LEFT JOIN (?) p ON p.actividad_id = ?", penalizaciones, idActividad).
+		Order("titulo").
+		Scan(&filas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range filas {
+		filas[i].TasaCancelacion = porcentaje(filas[i].Cancelaciones, filas[i].Inscripciones)
+	}
+	return filas, nil
+}
+
+// ReporteFranjas calcula la ocupación de cada día y horario, ordenada de la
+// más a la menos concurrida (o al revés si ascendente es true).
+func ReporteFranjas(rango RangoReporte, ascendente bool, limite int) ([]FilaFranja, error) {
+	var filas []FilaFranja
+	err := config.DB.Model(&models.Actividad{}).
+		Select(`dia, horario,
+			COUNT(*) AS actividades,
+			SUM(cupo_maximo) AS cupo_total,
+			COALESCE(SUM(i.total), 0) AS inscripciones`).
+		Joins("LEFT JOIN (?) i ON i.actividad_id = ?", inscripcionesVigentes(rango), idActividad).
+		Group("dia, horario").
+		Scan(&filas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range filas {
+		filas[i].Ocupacion = porcentaje(filas[i].Inscripciones, filas[i].CupoTotal)
+	}
+	sort.SliceStable(filas, func(i, j int) bool {
+		if ascendente {
+			return filas[i].Ocupacion < filas[j].Ocupacion
+		}
+		return filas[i].Ocupacion > filas[j].Ocupacion
+	})
+	if limite > 0 && len(filas) > limite {
+		filas = filas[:limite]
+	}
+	return filas, nil
+}
+
+func porcentaje(parte, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(parte)*10000/float64(total)) / 100
+}
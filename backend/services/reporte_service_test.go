@@ -0,0 +1,205 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestNuevoRangoReporte(t *testing.T) {
+	ahora := time.Date(2024, time.May, 15, 16, 30, 0, 0, time.UTC)
+	dia := func(d int) time.Time { return time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC) }
+
+	casos := []struct {
+		nombre       string
+		desde, hasta string
+		esperado     RangoReporte
+		valido       bool
+	}{
+		{"últimos 30 días por defecto", "", "", RangoReporte{Desde: time.Date(2024, time.April, 16, 0, 0, 0, 0, time.UTC), Hasta: dia(16)}, true},
+		{"hasta incluye el día", "2024-05-01", "2024-05-10", RangoReporte{Desde: dia(1), Hasta: dia(11)}, true},
+		{"un solo día", "2024-05-10", "2024-05-10", RangoReporte{Desde: dia(10), Hasta: dia(11)}, true},
+		{"desde posterior a hasta", "2024-05-11", "2024-05-10", RangoReporte{}, false},
+		{"fecha mal formada", "10/05/2024", "", RangoReporte{}, false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			rango, err := NuevoRangoReporte(caso.desde, caso.hasta, ahora)
+			if (err == nil) != caso.valido {
+				t.Fatalf("err = %v, válido esperado %v", err, caso.valido)
+			}
+			if caso.valido && (!rango.Desde.Equal(caso.esperado.Desde) || !rango.Hasta.Equal(caso.esperado.Hasta)) {
+				t.Errorf("rango = %v - %v, se esperaba %v - %v", rango.Desde, rango.Hasta, caso.esperado.Desde, caso.esperado.Hasta)
+			}
+		})
+	}
+}
+
+// prepararReportes carga dos actividades con altas, bajas y penalizaciones
+// dentro de mayo de 2024 y devuelve el rango del mes.
+func prepararReportes(t *testing.T) (yoga, boxeo *models.Actividad, rango RangoReporte) {
+	t.Helper()
+	prepararDB(t)
+	fecha := func(d int) *time.Time {
+		f := time.Date(2024, time.May, d, 10, 0, 0, 0, time.Local)
+		return &f
+	}
+
+	yoga = nuevaActividad("Yoga", "Lunes", "18:00", 4)
+	yoga.Categoria = "Bienestar"
+	boxeo = nuevaActividad("Boxeo", "Lunes", "18:00", 2)
+	boxeo.Categoria = "Combate"
+	eliminada := nuevaActividad("Zumba", "Martes", "10:00", 10)
+	crear(t, yoga, boxeo, eliminada)
+	config.DB.Delete(eliminada)
+
+	var socios []*models.Usuario
+	for _, email := range []string{"a@gym.test", "b@gym.test", "c@gym.test"} {
+		socio := nuevoUsuario(email)
+		crear(t, socio)
+		socios = append(socios, socio)
+	}
+
+	// Yoga: tres altas en semanas distintas, una dada de baja el 20
+	var inscripcionesYoga []*models.Inscripcion
+	for i, d := range []int{6, 7, 14} {
+		inscripcion := &models.Inscripcion{UsuarioID: socios[i].ID, ActividadID: yoga.ID, FechaInscripcion: fecha(d)}
+		crear(t, inscripcion)
+		inscripcionesYoga = append(inscripcionesYoga, inscripcion)
+	}
+	config.DB.Model(inscripcionesYoga[2]).Update("deleted_at", fecha(20))
+	// Boxeo: una alta, fuera de la tendencia de mayo pero vigente en el rango
+	crear(t, &models.Inscripcion{UsuarioID: socios[0].ID, ActividadID: boxeo.ID, FechaInscripcion: fecha(6)})
+
+	crear(t,
+		&models.Penalizacion{UsuarioID: socios[2].ID, ActividadID: yoga.ID, InscripcionID: inscripcionesYoga[2].ID,
+			Tipo: models.PenalizacionCancelacionTardia, Estado: models.PenalizacionActiva},
+		&models.Penalizacion{UsuarioID: socios[0].ID, ActividadID: yoga.ID, InscripcionID: inscripcionesYoga[0].ID,
+			Tipo: models.PenalizacionAusencia, Estado: models.PenalizacionActiva},
+	)
+
+	rango = RangoReporte{
+		Desde: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local),
+		Hasta: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local),
+	}
+	return yoga, boxeo, rango
+}
+
+func TestReporteOcupacion(t *testing.T) {
+	_, _, rango := prepararReportes(t)
+
+	casos := []struct {
+		agrupacion string
+		esperadas  []FilaOcupacion
+	}{
+		{"actividad", []FilaOcupacion{
+			{Grupo: "Boxeo", Actividades: 1, CupoTotal: 2, Inscripciones: 1, Ocupacion: 50},
+			{Grupo: "Yoga", Actividades: 1, CupoTotal: 4, Inscripciones: 3, Ocupacion: 75},
+		}},
+		{"categoria", []FilaOcupacion{
+			{Grupo: "Bienestar", Actividades: 1, CupoTotal: 4, Inscripciones: 3, Ocupacion: 75},
+			{Grupo: "Combate", Actividades: 1, CupoTotal: 2, Inscripciones: 1, Ocupacion: 50},
+		}},
+		{"dia", []FilaOcupacion{
+			{Grupo: "Lunes", Actividades: 2, CupoTotal: 6, Inscripciones: 4, Ocupacion: 66.67},
+		}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.agrupacion, func(t *testing.T) {
+			filas, err := ReporteOcupacion(caso.agrupacion, rango)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(filas) != len(caso.esperadas) {
+				t.Fatalf("filas = %+v, se esperaban %+v", filas, caso.esperadas)
+			}
+			for i := range filas {
+				if filas[i] != caso.esperadas[i] {
+					t.Errorf("fila %d = %+v, se esperaba %+v", i, filas[i], caso.esperadas[i])
+				}
+			}
+		})
+	}
+
+	if _, err := ReporteOcupacion("sala", rango); err == nil {
+		t.Error("se esperaba un error con una agrupación desconocida")
+	}
+}
+
+func TestReporteTendencia(t *testing.T) {
+	_, _, rango := prepararReportes(t)
+
+	casos := []struct {
+		periodo   string
+		esperadas []FilaTendencia
+	}{
+		{"dia", []FilaTendencia{
+			{Periodo: "2024-05-06", Inscripciones: 2},
+			{Periodo: "2024-05-07", Inscripciones: 1},
+			{Periodo: "2024-05-14", Inscripciones: 1},
+			{Periodo: "2024-05-20", Cancelaciones: 1},
+		}},
+		{"semana", []FilaTendencia{
+			{Periodo: "2024-W19", Inscripciones: 3},
+			{Periodo: "2024-W20", Inscripciones: 1},
+			{Periodo: "2024-W21", Cancelaciones: 1},
+		}},
+		{"mes", []FilaTendencia{
+			{Periodo: "2024-05", Inscripciones: 4, Cancelaciones: 1},
+		}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.periodo, func(t *testing.T) {
+			filas, err := ReporteTendencia(caso.periodo, rango)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(filas) != len(caso.esperadas) {
+				t.Fatalf("filas = %+v, se esperaban %+v", filas, caso.esperadas)
+			}
+			for i := range filas {
+				if filas[i] != caso.esperadas[i] {
+					t.Errorf("fila %d = %+v, se esperaba %+v", i, filas[i], caso.esperadas[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReporteCancelaciones(t *testing.T) {
+	yoga, boxeo, rango := prepararReportes(t)
+	// Las penalizaciones se crean con la fecha actual
+	rango.Hasta = time.Now().Add(time.Hour)
+
+	filas, err := ReporteCancelaciones(rango)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperadas := []FilaCancelaciones{
+		{ActividadID: boxeo.ID, Actividad: "Boxeo", Inscripciones: 1},
+		{ActividadID: yoga.ID, Actividad: "Yoga", Inscripciones: 3, Cancelaciones: 1, Tardias: 1, Ausencias: 1, TasaCancelacion: 33.33},
+	}
+	if len(filas) != len(esperadas) {
+		t.Fatalf("filas = %+v, se esperaban %+v", filas, esperadas)
+	}
+	for i := range filas {
+		if filas[i] != esperadas[i] {
+			t.Errorf("fila %d = %+v, se esperaba %+v", i, filas[i], esperadas[i])
+		}
+	}
+}
+
+func TestReporteFranjas(t *testing.T) {
+	_, _, rango := prepararReportes(t)
+
+	filas, err := ReporteFranjas(rango, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperada := FilaFranja{Dia: "Lunes", Horario: "18:00", Actividades: 2, CupoTotal: 6, Inscripciones: 4, Ocupacion: 66.67}
+	if len(filas) != 1 || filas[0] != esperada {
+		t.Errorf("filas = %+v, se esperaba [%+v]", filas, esperada)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"time"
+
+	"proyecto-gym-backend/models"
+)
+
+var diasSemana = map[string]time.Weekday{
+	"Domingo":   time.Sunday,
+	"Lunes":     time.Monday,
+	"Martes":    time.Tuesday,
+	"Miércoles": time.Wednesday,
+	"Jueves":    time.Thursday,
+	"Viernes":   time.Friday,
+	"Sábado":    time.Saturday,
+}
+
+// HoraInicio interpreta el horario "HH:MM" de una actividad. Las actividades
+// en "Horario Libre" no tienen una hora fija y devuelven ok = false.
+func HoraInicio(actividad *models.Actividad) (hora, minuto int, ok bool) {
+	t, err := time.Parse("15:04", actividad.Horario)
+	if err != nil {
+		return 0, 0, false
+	}
+	return t.Hour(), t.Minute(), true
+}
+
+// ProximaSesion calcula el próximo inicio de clase de una actividad semanal
+// a partir de desde (incluido).
+func ProximaSesion(actividad *models.Actividad, desde time.Time) (time.Time, bool) {
+	dia, ok := diasSemana[actividad.Dia]
+	if !ok {
+		return time.Time{}, false
+	}
+	hora, minuto, ok := HoraInicio(actividad)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	dias := (int(dia) - int(desde.Weekday()) + 7) % 7
+	sesion := time.Date(desde.Year(), desde.Month(), desde.Day()+dias, hora, minuto, 0, 0, desde.Location())
+	if sesion.Before(desde) {
+		sesion = sesion.AddDate(0, 0, 7)
+	}
+	return sesion, true
+}
+
+// UltimaSesion devuelve el inicio de la clase más reciente anterior a hasta.
+func UltimaSesion(actividad *models.Actividad, hasta time.Time) (time.Time, bool) {
+	proxima, ok := ProximaSesion(actividad, hasta)
+	if !ok {
+		return time.Time{}, false
+	}
+	return proxima.AddDate(0, 0, -7), true
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/models"
+)
+
+func TestProximaSesion(t *testing.T) {
+	// Miércoles 15 de mayo de 2024, 10:00
+	desde := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+	casos := []struct {
+		nombre   string
+		dia      string
+		horario  string
+		esperada time.Time
+		ok       bool
+	}{
+		{"más tarde el mismo día", "Miércoles", "18:30", time.Date(2024, time.May, 15, 18, 30, 0, 0, time.UTC), true},
+		{"justo ahora cuenta como próxima", "Miércoles", "10:00", desde, true},
+		{"ya pasó hoy pasa a la semana siguiente", "Miércoles", "09:59", time.Date(2024, time.May, 22, 9, 59, 0, 0, time.UTC), true},
+		{"día posterior de la semana", "Viernes", "07:00", time.Date(2024, time.May, 17, 7, 0, 0, 0, time.UTC), true},
+		{"día anterior de la semana", "Lunes", "20:00", time.Date(2024, time.May, 20, 20, 0, 0, 0, time.UTC), true},
+		{"cruza de mes", "Domingo", "09:00", time.Date(2024, time.May, 19, 9, 0, 0, 0, time.UTC), true},
+		{"horario libre", "Martes", "Horario Libre", time.Time{}, false},
+		{"día desconocido", "Feriado", "10:00", time.Time{}, false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			actividad := &models.Actividad{Dia: caso.dia, Horario: caso.horario}
+			sesion, ok := ProximaSesion(actividad, desde)
+			if ok != caso.ok || !sesion.Equal(caso.esperada) {
+				t.Errorf("ProximaSesion = %v, %v; se esperaba %v, %v", sesion, ok, caso.esperada, caso.ok)
+			}
+		})
+	}
+}
+
+func TestProximaSesionFinDeMes(t *testing.T) {
+	// Viernes 31 de mayo de 2024: la clase del lunes cae en junio
+	desde := time.Date(2024, time.May, 31, 12, 0, 0, 0, time.UTC)
+	sesion, ok := ProximaSesion(&models.Actividad{Dia: "Lunes", Horario: "08:00"}, desde)
+	esperada := time.Date(2024, time.June, 3, 8, 0, 0, 0, time.UTC)
+	if !ok || !sesion.Equal(esperada) {
+		t.Errorf("ProximaSesion = %v, %v; se esperaba %v", sesion, ok, esperada)
+	}
+}
+
+func TestUltimaSesion(t *testing.T) {
+	hasta := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+	casos := []struct {
+		nombre   string
+		dia      string
+		horario  string
+		esperada time.Time
+		ok       bool
+	}{
+		{"hoy más temprano", "Miércoles", "08:00", time.Date(2024, time.May, 15, 8, 0, 0, 0, time.UTC), true},
+		{"hoy más tarde es la de la semana pasada", "Miércoles", "18:00", time.Date(2024, time.May, 8, 18, 0, 0, 0, time.UTC), true},
+		{"día anterior de la semana", "Lunes", "19:00", time.Date(2024, time.May, 13, 19, 0, 0, 0, time.UTC), true},
+		{"día posterior de la semana", "Sábado", "10:00", time.Date(2024, time.May, 11, 10, 0, 0, 0, time.UTC), true},
+		{"horario libre", "Lunes", "Horario Libre", time.Time{}, false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			actividad := &models.Actividad{Dia: caso.dia, Horario: caso.horario}
+			sesion, ok := UltimaSesion(actividad, hasta)
+			if ok != caso.ok || !sesion.Equal(caso.esperada) {
+				t.Errorf("UltimaSesion = %v, %v; se esperaba %v, %v", sesion, ok, caso.esperada, caso.ok)
+			}
+		})
+	}
+}
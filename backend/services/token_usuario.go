@@ -0,0 +1,70 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// emitirTokenUsuario genera un token de un solo uso y guarda su hash.
+// Invalida los tokens del mismo tipo que el usuario tuviera sin usar, para
+// que sólo sirva el último enlace enviado. Devuelve el token en claro, que
+// sólo viaja en el correo.
+func emitirTokenUsuario(tx *gorm.DB, usuarioID uint, tipo string, vigencia time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	if err := tx.Where("usuario_id = ? AND tipo = ? AND usado_at IS NULL", usuarioID, tipo).
+		Delete(&models.TokenUsuario{}).Error; err != nil {
+		return "", err
+	}
+
+	registro := models.TokenUsuario{
+		UsuarioID: usuarioID,
+		Tipo:      tipo,
+		Hash:      hashTokenUsuario(token),
+		ExpiraAt:  time.Now().Add(vigencia),
+	}
+	if err := tx.Create(&registro).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumirTokenUsuario marca el token como usado dentro de la transacción.
+// Devuelve ok = false si no existe, es de otro tipo, venció o ya se usó.
+func consumirTokenUsuario(tx *gorm.DB, token, tipo string) (registro *models.TokenUsuario, ok bool, err error) {
+	registro = &models.TokenUsuario{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hash = ? AND tipo = ?", hashTokenUsuario(token), tipo).First(registro).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	ahora := time.Now()
+	if registro.UsadoAt != nil || !registro.ExpiraAt.After(ahora) {
+		return nil, false, nil
+	}
+	registro.UsadoAt = &ahora
+	if err := tx.Model(registro).Update("usado_at", ahora).Error; err != nil {
+		return nil, false, err
+	}
+	return registro, true, nil
+}
+
+func hashTokenUsuario(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
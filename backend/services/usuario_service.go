@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Estados de una cuenta para filtrar el listado de usuarios
+const (
+	UsuarioActivo     = "activo"
+	UsuarioSuspendido = "suspendido"
+	UsuarioEliminado  = "eliminado"
+)
+
+var (
+	ErrCuentaSuspendida    = apperrors.Prohibido("CUENTA_SUSPENDIDA", "Tu cuenta está suspendida")
+	ErrCuentaPropia        = apperrors.Conflicto("CUENTA_PROPIA", "No puedes realizar esta acción sobre tu propia cuenta")
+	ErrUsuarioYaSuspendido = apperrors.Conflicto("USUARIO_YA_SUSPENDIDO", "El usuario ya está suspendido")
+	ErrUsuarioNoSuspendido = apperrors.Conflicto("USUARIO_NO_SUSPENDIDO", "El usuario no está suspendido")
+	ErrUsuarioNoEliminado  = apperrors.Conflicto("USUARIO_NO_ELIMINADO", "El usuario no está eliminado")
+)
+
+type FiltroUsuarios struct {
+	Busqueda  string
+	Tipo      string
+	Estado    string
+	Pagina    int
+	PorPagina int
+}
+
+type ActualizarUsuarioRequest struct {
+	Nombre string `json:"nombre" binding:"required"`
+	Email  string `json:"email" binding:"required,email"`
+}
+
+// ListarUsuarios devuelve una página de usuarios y el total que cumple el
+// filtro. Los eliminados sólo aparecen al filtrar por ese estado.
+func ListarUsuarios(filtro FiltroUsuarios) ([]models.Usuario, int64, error) {
+	query := config.DB.Model(&models.Usuario{})
+
+	if filtro.Busqueda != "" {
+		patron := "%" + escaparLike(filtro.Busqueda) + "%"
+		query = query.Where("nombre LIKE ? ESCAPE ? OR email LIKE ? ESCAPE ?", patron, `\`, patron, `\`)
+	}
+
+	if filtro.Tipo != "" {
+		query = query.Where("tipo = ?", filtro.Tipo)
+	}
+
+	switch filtro.Estado {
+	case UsuarioActivo:
+		query = query.Where("suspendido_at IS NULL")
+	case UsuarioSuspendido:
+		query = query.Where("suspendido_at IS NOT NULL")
+	case UsuarioEliminado:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var usuarios []models.Usuario
+	if err := query.Order("id").Offset((filtro.Pagina - 1) * filtro.PorPagina).Limit(filtro.PorPagina).
+		Find(&usuarios).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return usuarios, total, nil
+}
+
+// escaparLike hace que %, _ y \ de la búsqueda se comparen literalmente en un
+// LIKE con ESCAPE '\'. El carácter de escape se pasa como parámetro porque
+// MySQL y SQLite no interpretan igual la barra dentro de un literal.
+var escaparLike = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace
+
+// GetUsuario busca un usuario, incluidos los eliminados.
+func GetUsuario(id uint) (*models.Usuario, error) {
+	var usuario models.Usuario
+	if err := config.DB.Unscoped().First(&usuario, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+// GetUsuarioSesion devuelve el usuario dueño de un token. Los eliminados no
+// tienen sesión válida.
+func GetUsuarioSesion(id uint) (*models.Usuario, error) {
+	var usuario models.Usuario
+	if err := config.DB.Select("id", "tipo", "idioma", "suspendido_at", "version_sesion").First(&usuario, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+// ActualizarUsuario cambia el nombre y el email. Un email nuevo vuelve a
+// quedar sin verificar y se le envía el enlace de verificación.
+func ActualizarUsuario(id uint, req ActualizarUsuarioRequest) (*models.Usuario, error) {
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cambiaEmail := req.Email != usuario.Email
+	if cambiaEmail {
+		var count int64
+		config.DB.Unscoped().Model(&models.Usuario{}).Where("email = ? AND id <> ?", req.Email, id).Count(&count)
+		if count > 0 {
+			return nil, ErrEmailRegistrado
+		}
+	}
+
+	usuario.Nombre = req.Nombre
+	usuario.Email = req.Email
+	if cambiaEmail {
+		usuario.EmailVerificadoAt = nil
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Save(usuario).Error; err != nil {
+			return err
+		}
+		// Una cuenta eliminada no recibe correos; al restaurarla puede pedir el reenvío
+		if !cambiaEmail || usuario.DeletedAt.Valid {
+			return nil
+		}
+		return EncolarVerificacionEmail(tx, usuario.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// CambiarTipoUsuario cambia el rol de un usuario. Un administrador no puede
+// quitarse el rol a sí mismo para que siempre quede al menos uno.
+func CambiarTipoUsuario(adminID, id uint, tipo string) (*models.Usuario, error) {
+	if adminID == id {
+		return nil, ErrCuentaPropia
+	}
+
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario.Tipo = tipo
+	if err := config.DB.Unscoped().Model(usuario).Update("tipo", tipo).Error; err != nil {
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// SuspenderUsuario bloquea el acceso de la cuenta: no puede iniciar sesión y
+// sus tokens dejan de ser válidos.
+func SuspenderUsuario(adminID, id uint, motivo string) (*models.Usuario, error) {
+	if adminID == id {
+		return nil, ErrCuentaPropia
+	}
+
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if usuario.SuspendidoAt != nil {
+		return nil, ErrUsuarioYaSuspendido
+	}
+
+	ahora := time.Now()
+	usuario.SuspendidoAt = &ahora
+	usuario.SuspendidoPor = &adminID
+	usuario.MotivoSuspension = motivo
+	if err := config.DB.Unscoped().Save(usuario).Error; err != nil {
+		return nil, err
+	}
+	return usuario, nil
+}
+
+func ReactivarUsuario(id uint) (*models.Usuario, error) {
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if usuario.SuspendidoAt == nil {
+		return nil, ErrUsuarioNoSuspendido
+	}
+
+	usuario.SuspendidoAt = nil
+	usuario.SuspendidoPor = nil
+	usuario.MotivoSuspension = ""
+	if err := config.DB.Unscoped().Save(usuario).Error; err != nil {
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// EliminarUsuario hace un borrado lógico de la cuenta; se puede restaurar.
+func EliminarUsuario(adminID, id uint) error {
+	if adminID == id {
+		return ErrCuentaPropia
+	}
+
+	resultado := config.DB.Delete(&models.Usuario{}, id)
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return ErrUsuarioNoEncontrado
+	}
+	return nil
+}
+
+func RestaurarUsuario(id uint) (*models.Usuario, error) {
+	usuario, err := GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !usuario.DeletedAt.Valid {
+		return nil, ErrUsuarioNoEliminado
+	}
+
+	if err := config.DB.Unscoped().Model(usuario).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	usuario.DeletedAt = gorm.DeletedAt{}
+	return usuario, nil
+}
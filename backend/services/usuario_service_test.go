@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+)
+
+func TestActualizarUsuario(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		email      string
+		eliminado  bool
+		err        error
+		verificado bool
+		correos    int64
+	}{
+		{"sólo nombre", "socio@gym.test", false, nil, true, 0},
+		{"email nuevo", "nuevo@gym.test", false, nil, false, 1},
+		{"email nuevo de cuenta eliminada", "nuevo@gym.test", true, nil, false, 0},
+		{"email de otra cuenta", "otro@gym.test", false, ErrEmailRegistrado, true, 0},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			socio := nuevoUsuario("socio@gym.test")
+			crear(t, socio, nuevoUsuario("otro@gym.test"))
+			if caso.eliminado {
+				config.DB.Delete(socio)
+			}
+
+			_, err := ActualizarUsuario(socio.ID, ActualizarUsuarioRequest{Nombre: "Renombrado", Email: caso.email})
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("err = %v, se esperaba %v", err, caso.err)
+			}
+
+			usuario, err := GetUsuario(socio.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verificado := usuario.EmailVerificadoAt != nil; verificado != caso.verificado {
+				t.Errorf("email verificado = %v, se esperaba %v", verificado, caso.verificado)
+			}
+
+			var correos int64
+			config.DB.Model(&models.Notificacion{}).
+				Where("usuario_id = ? AND plantilla = ?", socio.ID, PlantillaVerificarEmail).Count(&correos)
+			if correos != caso.correos {
+				t.Fatalf("se encolaron %d correos de verificación, se esperaban %d", correos, caso.correos)
+			}
+			if correos == 0 {
+				return
+			}
+
+			var notificacion models.Notificacion
+			config.DB.Where("usuario_id = ? AND plantilla = ?", socio.ID, PlantillaVerificarEmail).First(&notificacion)
+			if notificacion.Destinatario != caso.email {
+				t.Errorf("el correo se envió a %s, se esperaba %s", notificacion.Destinatario, caso.email)
+			}
+			token := tokenDelCorreo(t, socio.ID, PlantillaVerificarEmail, "/verificar-email")
+			if _, err := VerificarEmail(token, "10.0.0.1"); err != nil {
+				t.Fatalf("verificando el email nuevo: %v", err)
+			}
+			if !EmailVerificado(socio.ID) {
+				t.Error("el email nuevo no quedó verificado")
+			}
+		})
+	}
+}
+
+func TestListarUsuariosBusqueda(t *testing.T) {
+	prepararDB(t)
+	crear(t, nuevoUsuario("ana_perez@gym.test"), nuevoUsuario("anaperez@gym.test"),
+		nuevoUsuario("100%socio@gym.test"), nuevoUsuario(`barra\socio@gym.test`))
+
+	casos := []struct {
+		busqueda string
+		emails   []string
+	}{
+		{"ana", []string{"ana_perez@gym.test", "anaperez@gym.test"}},
+		{"ana_", []string{"ana_perez@gym.test"}},
+		{"_", []string{"ana_perez@gym.test"}},
+		{"%", []string{"100%socio@gym.test"}},
+		{`\`, []string{`barra\socio@gym.test`}},
+		{`a\s`, []string{`barra\socio@gym.test`}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.busqueda, func(t *testing.T) {
+			usuarios, total, err := ListarUsuarios(FiltroUsuarios{Busqueda: caso.busqueda, Pagina: 1, PorPagina: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var emails []string
+			for _, usuario := range usuarios {
+				emails = append(emails, usuario.Email)
+			}
+			if total != int64(len(caso.emails)) || strings.Join(emails, ",") != strings.Join(caso.emails, ",") {
+				t.Errorf("encontró %v (total %d), se esperaba %v", emails, total, caso.emails)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"proyecto-gym-backend/apperrors"
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailNoVerificado         = apperrors.Prohibido("EMAIL_NO_VERIFICADO", "Verifica tu email para poder inscribirte en actividades")
+	ErrEmailYaVerificado         = apperrors.Conflicto("EMAIL_YA_VERIFICADO", "El email ya está verificado")
+	ErrTokenVerificacionInvalido = apperrors.Invalido("TOKEN_VERIFICACION_INVALIDO", "El enlace de verificación es inválido o ya venció")
+)
+
+// EncolarVerificacionEmail genera el token de verificación y encola el
+// correo con el enlace. Usa la transacción recibida, de modo que el correo
+// sólo se envíe si el alta del usuario se confirma.
+func EncolarVerificacionEmail(tx *gorm.DB, usuarioID uint) error {
+	horas := config.App.Cuentas.VerificacionHoras
+	token, err := emitirTokenUsuario(tx, usuarioID, models.TokenVerificacion, time.Duration(horas)*time.Hour)
+	if err != nil {
+		return err
+	}
+	return EncolarNotificacion(tx, usuarioID, PlantillaVerificarEmail, map[string]interface{}{
+		"Enlace": enlaceCuenta("/verificar-email", token),
+		"Horas":  horas,
+	})
+}
+
+// ReenviarVerificacionEmail envía un enlace nuevo; el anterior deja de valer.
+func ReenviarVerificacionEmail(usuarioID uint) error {
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, usuarioID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUsuarioNoEncontrado
+		}
+		return err
+	}
+	if usuario.EmailVerificadoAt != nil {
+		return ErrEmailYaVerificado
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return EncolarVerificacionEmail(tx, usuario.ID)
+	})
+}
+
+// VerificarEmail consume el token del enlace y marca el email como verificado.
+func VerificarEmail(token, ip string) (*models.Usuario, error) {
+	var usuario models.Usuario
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		registro, ok, err := consumirTokenUsuario(tx, token, models.TokenVerificacion)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTokenVerificacionInvalido
+		}
+
+		if err := tx.First(&usuario, registro.UsuarioID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTokenVerificacionInvalido // la cuenta se eliminó después del registro
+			}
+			return err
+		}
+		if usuario.EmailVerificadoAt != nil {
+			return nil
+		}
+		ahora := time.Now()
+		usuario.EmailVerificadoAt = &ahora
+		return tx.Model(&usuario).Update("email_verificado_at", ahora).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	RegistrarAuditoria(models.AuditoriaEmailVerificado, &usuario.ID, nil, ip, nil)
+	return &usuario, nil
+}
+
+// EmailVerificado indica si el usuario ya confirmó su email. Sin email
+// verificado no puede inscribirse ni pagar inscripciones.
+func EmailVerificado(usuarioID uint) bool {
+	var count int64
+	config.DB.Model(&models.Usuario{}).Where("id = ? AND email_verificado_at IS NOT NULL", usuarioID).Count(&count)
+	return count > 0
+}
+
+// MarcarEmailsVerificados da por verificadas las cuentas que existían antes
+// de exigir la verificación. Se llama sólo cuando la migración agrega la columna.
+func MarcarEmailsVerificados() error {
+	return config.DB.Unscoped().Model(&models.Usuario{}).Where("email_verificado_at IS NULL").
+		Update("email_verificado_at", time.Now()).Error
+}
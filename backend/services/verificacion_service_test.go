@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"proyecto-gym-backend/config"
+	"proyecto-gym-backend/models"
+
+	"gorm.io/gorm"
+)
+
+func TestVerificarEmail(t *testing.T) {
+	casos := []struct {
+		nombre string
+		token  func(t *testing.T, usuarioID uint) string
+		valido bool
+	}{
+		{"vigente", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenVerificacion, time.Hour)
+		}, true},
+		{"vencido", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenVerificacion, -time.Minute)
+		}, false},
+		{"de recuperación", func(t *testing.T, usuarioID uint) string {
+			return emitir(t, usuarioID, models.TokenRecuperacion, time.Hour)
+		}, false},
+		{"inexistente", func(t *testing.T, usuarioID uint) string {
+			return "no-existe"
+		}, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			prepararDB(t)
+			socio := nuevoUsuario("socio@gym.test")
+			socio.EmailVerificadoAt = nil
+			crear(t, socio)
+			token := caso.token(t, socio.ID)
+
+			_, err := VerificarEmail(token, "10.0.0.1")
+			if !caso.valido {
+				if !errors.Is(err, ErrTokenVerificacionInvalido) {
+					t.Fatalf("err = %v, se esperaba %v", err, ErrTokenVerificacionInvalido)
+				}
+				if EmailVerificado(socio.ID) {
+					t.Fatal("el email quedó verificado con un token inválido")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !EmailVerificado(socio.ID) {
+				t.Fatal("el email no quedó verificado")
+			}
+			if _, err := VerificarEmail(token, "10.0.0.1"); !errors.Is(err, ErrTokenVerificacionInvalido) {
+				t.Fatalf("segundo uso: err = %v, se esperaba %v", err, ErrTokenVerificacionInvalido)
+			}
+		})
+	}
+}
+
+func TestReenviarVerificacionEmail(t *testing.T) {
+	prepararDB(t)
+	socio := nuevoUsuario("socio@gym.test")
+	socio.EmailVerificadoAt = nil
+	crear(t, socio)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return EncolarVerificacionEmail(tx, socio.ID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	anterior := tokenDelCorreo(t, socio.ID, PlantillaVerificarEmail, "/verificar-email")
+
+	if err := ReenviarVerificacionEmail(socio.ID); err != nil {
+		t.Fatal(err)
+	}
+	nuevo := tokenDelCorreo(t, socio.ID, PlantillaVerificarEmail, "/verificar-email")
+
+	// El reenvío invalida el enlace anterior
+	if _, err := VerificarEmail(anterior, "10.0.0.1"); !errors.Is(err, ErrTokenVerificacionInvalido) {
+		t.Fatalf("enlace anterior: err = %v, se esperaba %v", err, ErrTokenVerificacionInvalido)
+	}
+	if _, err := VerificarEmail(nuevo, "10.0.0.1"); err != nil {
+		t.Fatalf("enlace reenviado: %v", err)
+	}
+	if err := ReenviarVerificacionEmail(socio.ID); !errors.Is(err, ErrEmailYaVerificado) {
+		t.Fatalf("ya verificado: err = %v, se esperaba %v", err, ErrEmailYaVerificado)
+	}
+}
@@ -5,6 +5,9 @@ import Navbar from './components/Navbar';
 import Home from './pages/Home';
 import Login from './pages/Login';
 import Register from './pages/Register'; // ← NUEVA IMPORTACIÓN
+import OlvidePassword from './pages/OlvidePassword';
+import RestablecerPassword from './pages/RestablecerPassword';
+import VerificarEmail from './pages/VerificarEmail';
 import ActividadDetalle from './pages/ActividadDetalle';
 import MisActividades from './pages/MisActividades';
 import AdminPanel from './pages/AdminPanel';
@@ -24,6 +27,9 @@ function App() {
                             <Route path="/" element={<Home />} />
                             <Route path="/login" element={<Login />} />
                             <Route path="/register" element={<Register />} /> {/* ← NUEVA RUTA */}
+                            <Route path="/olvide-password" element={<OlvidePassword />} />
+                            <Route path="/restablecer-password" element={<RestablecerPassword />} />
+                            <Route path="/verificar-email" element={<VerificarEmail />} />
                             <Route path="/actividades/:id" element={<ActividadDetalle />} />
                             <Route path="/mis-actividades" element={<MisActividades />} />
 
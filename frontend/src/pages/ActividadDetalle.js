@@ -69,7 +69,6 @@ const ActividadDetalle = () => {
 
         try {
             await inscripcionesAPI.create({
-                usuario_id: user.id,
                 actividad_id: parseInt(id)
             });
 
@@ -91,6 +91,15 @@ const Login = () => {
                         </button>
                     </form>
 
+                    <div style={{ marginTop: '15px', textAlign: 'center' }}>
+                        <Link
+                            to="/olvide-password"
+                            style={{ color: '#667eea', textDecoration: 'none' }}
+                        >
+                            ¿Olvidaste tu contraseña?
+                        </Link>
+                    </div>
+
                     <div style={{
                         marginTop: '25px',
                         textAlign: 'center',
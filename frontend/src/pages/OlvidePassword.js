@@ -0,0 +1,92 @@
+import React, { useState } from 'react';
+import { Link } from 'react-router-dom';
+import { authAPI } from '../services/api';
+
+const OlvidePassword = () => {
+    const [email, setEmail] = useState('');
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+    const [success, setSuccess] = useState('');
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+        setLoading(true);
+        setError('');
+
+        try {
+            const response = await authAPI.forgotPassword(email);
+            setSuccess(response.data.message || 'Revisa tu correo');
+        } catch (err) {
+            setError(err.response?.data?.error || 'Error al solicitar el enlace');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="container">
+            <div style={{ maxWidth: '450px', margin: '50px auto' }}>
+                <div className="card">
+                    <div style={{ textAlign: 'center', marginBottom: '30px' }}>
+                        <h2 style={{
+                            background: 'linear-gradient(135deg, #667eea 0%, #764ba2 100%)',
+                            WebkitBackgroundClip: 'text',
+                            WebkitTextFillColor: 'transparent',
+                            fontSize: '32px',
+                            fontWeight: '700'
+                        }}>
+                            📨 Recuperar Contraseña
+                        </h2>
+                        <p style={{ color: '#718096', marginTop: '10px' }}>
+                            Te enviaremos un enlace para elegir una nueva
+                        </p>
+                    </div>
+
+                    {error && <div className="alert alert-error">{error}</div>}
+                    {success && <div className="alert alert-success">{success}</div>}
+
+                    {!success && (
+                        <form onSubmit={handleSubmit}>
+                            <div className="form-group">
+                                <label className="form-label">📧 Email:</label>
+                                <input
+                                    type="email"
+                                    value={email}
+                                    onChange={(e) => setEmail(e.target.value)}
+                                    required
+                                    className="form-control"
+                                    placeholder="tu@email.com"
+                                />
+                            </div>
+
+                            <button
+                                type="submit"
+                                disabled={loading}
+                                className="btn btn-primary"
+                                style={{ width: '100%', padding: '15px', fontSize: '16px', fontWeight: '600' }}
+                            >
+                                {loading ? '⏳ Enviando...' : '📨 Enviar Enlace'}
+                            </button>
+                        </form>
+                    )}
+
+                    <div style={{
+                        marginTop: '25px',
+                        textAlign: 'center',
+                        padding: '20px 0',
+                        borderTop: '1px solid #e2e8f0'
+                    }}>
+                        <Link
+                            to="/login"
+                            style={{ color: '#667eea', textDecoration: 'none', fontWeight: '600' }}
+                        >
+                            Volver a Iniciar Sesión
+                        </Link>
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default OlvidePassword;
\ No newline at end of file
@@ -0,0 +1,124 @@
+import React, { useState } from 'react';
+import { useSearchParams, Link } from 'react-router-dom';
+import { authAPI } from '../services/api';
+
+const RestablecerPassword = () => {
+    const [searchParams] = useSearchParams();
+    const token = searchParams.get('token') || '';
+
+    const [password, setPassword] = useState('');
+    const [confirmPassword, setConfirmPassword] = useState('');
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+    const [success, setSuccess] = useState('');
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+        setError('');
+
+        if (password !== confirmPassword) {
+            setError('Las contraseñas no coinciden');
+            return;
+        }
+
+        if (password.length < 6) {
+            setError('La contraseña debe tener al menos 6 caracteres');
+            return;
+        }
+
+        setLoading(true);
+        try {
+            const response = await authAPI.resetPassword(token, password);
+            setSuccess(response.data.message || 'Contraseña restablecida');
+        } catch (err) {
+            setError(err.response?.data?.error || 'Error al restablecer la contraseña');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="container">
+            <div style={{ maxWidth: '450px', margin: '50px auto' }}>
+                <div className="card">
+                    <div style={{ textAlign: 'center', marginBottom: '30px' }}>
+                        <h2 style={{
+                            background: 'linear-gradient(135deg, #667eea 0%, #764ba2 100%)',
+                            WebkitBackgroundClip: 'text',
+                            WebkitTextFillColor: 'transparent',
+                            fontSize: '32px',
+                            fontWeight: '700'
+                        }}>
+                            🔑 Nueva Contraseña
+                        </h2>
+                        <p style={{ color: '#718096', marginTop: '10px' }}>
+                            Elige la contraseña que usarás para ingresar
+                        </p>
+                    </div>
+
+                    {!token && (
+                        <div className="alert alert-error">
+                            El enlace no es válido. Solicita uno nuevo desde{' '}
+                            <Link to="/olvide-password">recuperar contraseña</Link>.
+                        </div>
+                    )}
+                    {error && <div className="alert alert-error">{error}</div>}
+                    {success && <div className="alert alert-success">{success}</div>}
+
+                    {token && !success && (
+                        <form onSubmit={handleSubmit}>
+                            <div className="form-group">
+                                <label className="form-label">🔒 Nueva contraseña:</label>
+                                <input
+                                    type="password"
+                                    value={password}
+                                    onChange={(e) => setPassword(e.target.value)}
+                                    required
+                                    className="form-control"
+                                    placeholder="Mínimo 6 caracteres"
+                                />
+                            </div>
+
+                            <div className="form-group">
+                                <label className="form-label">🔒 Confirmar contraseña:</label>
+                                <input
+                                    type="password"
+                                    value={confirmPassword}
+                                    onChange={(e) => setConfirmPassword(e.target.value)}
+                                    required
+                                    className="form-control"
+                                    placeholder="Repite la contraseña"
+                                />
+                            </div>
+
+                            <button
+                                type="submit"
+                                disabled={loading}
+                                className="btn btn-primary"
+                                style={{ width: '100%', padding: '15px', fontSize: '16px', fontWeight: '600' }}
+                            >
+                                {loading ? '⏳ Guardando...' : '💾 Guardar Contraseña'}
+                            </button>
+                        </form>
+                    )}
+
+                    <div style={{
+                        marginTop: '25px',
+                        textAlign: 'center',
+                        padding: '20px 0',
+                        borderTop: '1px solid #e2e8f0'
+                    }}>
+                        <Link
+                            to="/login"
+                            style={{ color: '#667eea', textDecoration: 'none', fontWeight: '600' }}
+                        >
+                            Volver a Iniciar Sesión
+                        </Link>
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default RestablecerPassword;
\ No newline at end of file
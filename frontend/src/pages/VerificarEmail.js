@@ -0,0 +1,81 @@
+import React, { useState, useEffect, useRef } from 'react';
+import { useSearchParams, Link } from 'react-router-dom';
+import { authAPI } from '../services/api';
+
+const VerificarEmail = () => {
+    const [searchParams] = useSearchParams();
+    const token = searchParams.get('token') || '';
+
+    const [loading, setLoading] = useState(true);
+    const [error, setError] = useState('');
+    const [success, setSuccess] = useState('');
+    const enviado = useRef(false);
+
+    useEffect(() => {
+        if (!token) {
+            setError('El enlace de verificación no es válido');
+            setLoading(false);
+            return;
+        }
+
+        // El token es de un solo uso: en modo estricto React ejecuta el
+        // efecto dos veces y la segunda petición lo daría por inválido
+        if (enviado.current) {
+            return;
+        }
+        enviado.current = true;
+
+        const verificar = async () => {
+            try {
+                const response = await authAPI.verifyEmail(token);
+                setSuccess(response.data.message || 'Email verificado');
+            } catch (err) {
+                setError(err.response?.data?.error || 'Error al verificar el email');
+            } finally {
+                setLoading(false);
+            }
+        };
+
+        verificar();
+    }, [token]);
+
+    return (
+        <div className="container">
+            <div style={{ maxWidth: '450px', margin: '50px auto' }}>
+                <div className="card">
+                    <div style={{ textAlign: 'center', marginBottom: '30px' }}>
+                        <h2 style={{
+                            background: 'linear-gradient(135deg, #667eea 0%, #764ba2 100%)',
+                            WebkitBackgroundClip: 'text',
+                            WebkitTextFillColor: 'transparent',
+                            fontSize: '32px',
+                            fontWeight: '700'
+                        }}>
+                            ✉️ Verificar Email
+                        </h2>
+                    </div>
+
+                    {loading && <div className="loading">Verificando</div>}
+                    {error && <div className="alert alert-error">{error}</div>}
+                    {success && <div className="alert alert-success">{success}</div>}
+
+                    <div style={{
+                        marginTop: '25px',
+                        textAlign: 'center',
+                        padding: '20px 0',
+                        borderTop: '1px solid #e2e8f0'
+                    }}>
+                        <Link
+                            to="/"
+                            style={{ color: '#667eea', textDecoration: 'none', fontWeight: '600' }}
+                        >
+                            Ir a las Actividades
+                        </Link>
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default VerificarEmail;
\ No newline at end of file
@@ -1,6 +1,6 @@
 import axios from 'axios';
 
-const API_URL = process.env.REACT_APP_API_URL || 'http://localhost:8080/api';
+const API_URL = process.env.REACT_APP_API_URL || 'http://localhost:8080/api/v1';
 
 const api = axios.create({
     baseURL: API_URL,
@@ -41,6 +41,9 @@ export const authAPI = {
     login: (email, password) => api.post('/login', { email, password }),
     register: (nombre, email, password, tipo = 'socio') =>
         api.post('/register', { nombre, email, password, tipo }),
+    forgotPassword: (email) => api.post('/password/forgot', { email }),
+    resetPassword: (token, password) => api.post('/password/reset', { token, password }),
+    verifyEmail: (token) => api.post('/email/verify', { token }),
 };
 
 // Actividades API